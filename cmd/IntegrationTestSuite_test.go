@@ -7,7 +7,7 @@ import (
 	"os"
 	"sync"
 
-	"github.com/google/go-github/v43/github"
+	"github.com/google/go-github/v57/github"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+const selectedActions = "selected"
+
+// workflowPermissions are the default permissions of the GITHUB_TOKEN. go-github does not support them for
+// repositories yet.
+type workflowPermissions struct {
+	DefaultWorkflowPermissions   string `json:"default_workflow_permissions"`
+	CanApprovePullRequestReviews bool   `json:"can_approve_pull_request_reviews"`
+}
+
+// actionsSettings are the GitHub Actions settings of a repository.
+type actionsSettings struct {
+	permissions *github.ActionsPermissionsRepository
+	// selectedActions are the allowed actions. They are only available if the allowed actions are "selected".
+	selectedActions *github.ActionsAllowed
+	workflow        workflowPermissions
+}
+
+// ActionsSettingsVerifier verifies if the GitHub Actions settings of a repository match the policy.
+type ActionsSettingsVerifier struct {
+	client   *github.Client
+	repoName string
+	policy   *ActionsPolicy
+	// report collects the findings. It may be nil.
+	report *findingsReport
+}
+
+type ActionsProblemHandler interface {
+	handleWrongActionsSettings(existing actionsSettings, differences []protectionDifference)
+}
+
+type LogActionsProblemHandler struct {
+	repoName string
+}
+
+func (handler LogActionsProblemHandler) handleWrongActionsSettings(existing actionsSettings, differences []protectionDifference) {
+	fmt.Printf("The repository %v has outdated GitHub Actions settings.\n", handler.repoName)
+	for _, difference := range differences {
+		fmt.Printf("  - %v\n", difference)
+	}
+}
+
+type FixActionsProblemHandler struct {
+	client   *github.Client
+	repoName string
+	policy   *ActionsPolicy
+}
+
+// handleWrongActionsSettings updates the groups of settings that contain differences. Settings that the policy does
+// not define keep their existing value.
+func (handler FixActionsProblemHandler) handleWrongActionsSettings(existing actionsSettings, differences []protectionDifference) {
+	if containsDifference(differences, "actions enabled") || containsDifference(differences, "allowed actions") {
+		request := github.ActionsPermissionsRepository{Enabled: existing.permissions.Enabled, AllowedActions: handler.policy.AllowedActions}
+		if handler.policy.Enabled != nil {
+			request.Enabled = handler.policy.Enabled
+		}
+		_, _, err := handler.client.Repositories.EditActionsPermissions(context.Background(), "exasol", handler.repoName, request)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to update GitHub Actions permissions of exasol/%v. Cause: %v", handler.repoName, err.Error()))
+		}
+	}
+	if containsAnyDifference(differences, "GitHub-owned actions allowed", "verified actions allowed", "allowed action patterns") {
+		handler.updateSelectedActions(existing.selectedActions)
+	}
+	if containsAnyDifference(differences, "default workflow permissions", "actions can approve pull requests") {
+		handler.updateWorkflowPermissions(existing.workflow)
+	}
+}
+
+func (handler FixActionsProblemHandler) updateSelectedActions(existing *github.ActionsAllowed) {
+	request := github.ActionsAllowed{}
+	if existing != nil {
+		request = *existing
+	}
+	selected := handler.policy.SelectedActions
+	if selected.GithubOwnedAllowed != nil {
+		request.GithubOwnedAllowed = selected.GithubOwnedAllowed
+	}
+	if selected.VerifiedAllowed != nil {
+		request.VerifiedAllowed = selected.VerifiedAllowed
+	}
+	if selected.PatternsAllowed != nil {
+		request.PatternsAllowed = selected.PatternsAllowed
+	}
+	_, _, err := handler.client.Repositories.EditActionsAllowed(context.Background(), "exasol", handler.repoName, request)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to update allowed actions of exasol/%v. Cause: %v", handler.repoName, err.Error()))
+	}
+}
+
+func (handler FixActionsProblemHandler) updateWorkflowPermissions(existing workflowPermissions) {
+	request := existing
+	if handler.policy.DefaultWorkflowPermissions != nil {
+		request.DefaultWorkflowPermissions = *handler.policy.DefaultWorkflowPermissions
+	}
+	if handler.policy.CanApprovePullRequestReviews != nil {
+		request.CanApprovePullRequestReviews = *handler.policy.CanApprovePullRequestReviews
+	}
+	httpRequest, err := handler.client.NewRequest("PUT", fmt.Sprintf("repos/exasol/%v/actions/permissions/workflow", handler.repoName), request)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create request for workflow permissions of exasol/%v. Cause: %v", handler.repoName, err.Error()))
+	}
+	_, err = handler.client.Do(context.Background(), httpRequest, nil)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to update workflow permissions of exasol/%v. Cause: %v", handler.repoName, err.Error()))
+	}
+}
+
+func (verifier ActionsSettingsVerifier) VerifyActionsSettings(fix bool) {
+	if verifier.policy.isEmpty() {
+		return
+	}
+	existing := verifier.getActionsSettings()
+	differences := diffActionsSettings(existing, verifier.policy)
+	if len(differences) == 0 {
+		return
+	}
+	for _, difference := range differences {
+		verifier.report.add(Finding{Kind: settingsDriftFinding, Repo: verifier.repoName, Attribute: difference.attribute,
+			Expected: difference.expected, Actual: difference.actual, Message: difference.String()})
+	}
+	verifier.getProblemHandler(fix).handleWrongActionsSettings(existing, differences)
+}
+
+func (verifier ActionsSettingsVerifier) getProblemHandler(fix bool) ActionsProblemHandler {
+	if fix {
+		return FixActionsProblemHandler{client: verifier.client, repoName: verifier.repoName, policy: verifier.policy}
+	} else {
+		return LogActionsProblemHandler{repoName: verifier.repoName}
+	}
+}
+
+func (verifier ActionsSettingsVerifier) getActionsSettings() actionsSettings {
+	permissions, _, err := verifier.client.Repositories.GetActionsPermissions(context.Background(), "exasol", verifier.repoName)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get GitHub Actions permissions of exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+	}
+	settings := actionsSettings{permissions: permissions}
+	if permissions.GetAllowedActions() == selectedActions {
+		settings.selectedActions, _, err = verifier.client.Repositories.GetActionsAllowed(context.Background(), "exasol", verifier.repoName)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to get allowed actions of exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+		}
+	}
+	request, err := verifier.client.NewRequest("GET", fmt.Sprintf("repos/exasol/%v/actions/permissions/workflow", verifier.repoName), nil)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create request for workflow permissions of exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+	}
+	_, err = verifier.client.Do(context.Background(), request, &settings.workflow)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get workflow permissions of exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+	}
+	return settings
+}
+
+// diffActionsSettings compares the GitHub Actions settings with the policy. If the policy disables GitHub Actions, the
+// other settings don't matter.
+func diffActionsSettings(existing actionsSettings, policy *ActionsPolicy) []protectionDifference {
+	var differences []protectionDifference
+	differences = diffOptionalBool(differences, "actions enabled", policy.Enabled, existing.permissions.GetEnabled())
+	if policy.Enabled != nil && !*policy.Enabled {
+		return differences
+	}
+	differences = diffOptionalString(differences, "allowed actions", policy.AllowedActions, existing.permissions.GetAllowedActions())
+	if policy.AllowedActions != nil && *policy.AllowedActions == selectedActions {
+		differences = diffSelectedActions(differences, existing.selectedActions, policy.SelectedActions)
+	}
+	differences = diffOptionalString(differences, "default workflow permissions", policy.DefaultWorkflowPermissions, existing.workflow.DefaultWorkflowPermissions)
+	return diffOptionalBool(differences, "actions can approve pull requests", policy.CanApprovePullRequestReviews, existing.workflow.CanApprovePullRequestReviews)
+}
+
+// diffSelectedActions compares the allowed actions. Without existing selection, e.g. if the repository still allows
+// all actions, every defined setting differs.
+func diffSelectedActions(differences []protectionDifference, existing *github.ActionsAllowed, policy SelectedActionsPolicy) []protectionDifference {
+	differences = diffOptionalBool(differences, "GitHub-owned actions allowed", policy.GithubOwnedAllowed, existing.GetGithubOwnedAllowed())
+	differences = diffOptionalBool(differences, "verified actions allowed", policy.VerifiedAllowed, existing.GetVerifiedAllowed())
+	var existingPatterns []string
+	if existing != nil {
+		existingPatterns = existing.PatternsAllowed
+	}
+	if policy.PatternsAllowed != nil {
+		differences = diffList(differences, "allowed action patterns", policy.PatternsAllowed, existingPatterns)
+	}
+	return differences
+}
+
+func containsAnyDifference(differences []protectionDifference, attributes ...string) bool {
+	for _, attribute := range attributes {
+		if containsDifference(differences, attribute) {
+			return true
+		}
+	}
+	return false
+}
+
+func (policy *ActionsPolicy) isEmpty() bool {
+	return policy.Enabled == nil && policy.AllowedActions == nil && policy.DefaultWorkflowPermissions == nil &&
+		policy.CanApprovePullRequestReviews == nil
+}
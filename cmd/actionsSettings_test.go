@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/suite"
+)
+
+type ActionsSettingsSuite struct {
+	suite.Suite
+}
+
+func TestActionsSettingsSuite(t *testing.T) {
+	suite.Run(t, new(ActionsSettingsSuite))
+}
+
+func (suite *ActionsSettingsSuite) createSettings(enabled bool, allowedActions string) actionsSettings {
+	return actionsSettings{
+		permissions: &github.ActionsPermissionsRepository{Enabled: &enabled, AllowedActions: &allowedActions},
+		workflow:    workflowPermissions{DefaultWorkflowPermissions: "write", CanApprovePullRequestReviews: true},
+	}
+}
+
+func (suite *ActionsSettingsSuite) TestEmptyPolicyManagesNothing() {
+	policy := ActionsPolicy{}
+	suite.True(policy.isEmpty())
+	suite.Empty(diffActionsSettings(suite.createSettings(true, "all"), &policy))
+}
+
+func (suite *ActionsSettingsSuite) TestDifferences() {
+	enabled := true
+	allowedActions := "local_only"
+	permissions := "read"
+	canApprove := false
+	policy := ActionsPolicy{Enabled: &enabled, AllowedActions: &allowedActions, DefaultWorkflowPermissions: &permissions,
+		CanApprovePullRequestReviews: &canApprove}
+	suite.Equal([]protectionDifference{
+		{attribute: "allowed actions", expected: "local_only", actual: "all"},
+		{attribute: "default workflow permissions", expected: "read", actual: "write"},
+		{attribute: "actions can approve pull requests", expected: "false", actual: "true"},
+	}, diffActionsSettings(suite.createSettings(true, "all"), &policy))
+}
+
+func (suite *ActionsSettingsSuite) TestDisabledActionsIgnoreOtherSettings() {
+	enabled := false
+	permissions := "read"
+	policy := ActionsPolicy{Enabled: &enabled, DefaultWorkflowPermissions: &permissions}
+	suite.Equal([]protectionDifference{{attribute: "actions enabled", expected: "false", actual: "true"}},
+		diffActionsSettings(suite.createSettings(true, "all"), &policy))
+}
+
+func (suite *ActionsSettingsSuite) TestSelectedActions() {
+	allowedActions := "selected"
+	trueValue := true
+	policy := ActionsPolicy{AllowedActions: &allowedActions, SelectedActions: SelectedActionsPolicy{GithubOwnedAllowed: &trueValue,
+		PatternsAllowed: []string{"exasol/*", "aws-actions/*"}}}
+	settings := suite.createSettings(true, "selected")
+	settings.selectedActions = &github.ActionsAllowed{GithubOwnedAllowed: &trueValue, PatternsAllowed: []string{"exasol/*"}}
+	suite.Equal([]protectionDifference{
+		{attribute: "allowed action patterns", expected: "'exasol/*', 'aws-actions/*'", actual: "'exasol/*'"},
+	}, diffActionsSettings(settings, &policy))
+}
+
+func (suite *ActionsSettingsSuite) TestSwitchToSelectedActions() {
+	allowedActions := "selected"
+	trueValue := true
+	policy := ActionsPolicy{AllowedActions: &allowedActions, SelectedActions: SelectedActionsPolicy{GithubOwnedAllowed: &trueValue}}
+	suite.Equal([]protectionDifference{
+		{attribute: "allowed actions", expected: "selected", actual: "all"},
+		{attribute: "GitHub-owned actions allowed", expected: "true", actual: "false"},
+	}, diffActionsSettings(suite.createSettings(true, "all"), &policy))
+}
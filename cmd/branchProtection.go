@@ -4,71 +4,224 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strings"
 
-	"github.com/google/go-github/v43/github"
+	"github.com/google/go-github/v57/github"
 )
 
 type BranchProtectionVerifier struct {
 	repoName string
 	client   *github.Client
+	policy   *BranchProtectionPolicy
+	// reportedChecksHistory is the number of recent commits that are used to verify that the required checks are
+	// actually reported. 0 disables the verification.
+	reportedChecksHistory int
+	// report collects the differences to the expected protections. It may be nil.
+	report *findingsReport
+}
+
+// requiredCheck is a status check that github-keeper requires, together with a description of where it comes from.
+type requiredCheck struct {
+	context string
+	origin  string
+	// job and matrix describe the workflow job that produces the check. They are empty for other checks.
+	job    string
+	matrix string
+	// appId is the ID of the GitHub app that must report the check. 0 allows the app that recently reported it.
+	appId int64
+}
+
+// describeOrigin returns the origin of the check including the job and matrix combination.
+func (check requiredCheck) describeOrigin() string {
+	description := check.origin
+	if check.job != "" {
+		description += ", job " + check.job
+	}
+	if check.matrix != "" {
+		description += ", matrix " + check.matrix
+	}
+	return description
+}
+
+// protectedBranch is a branch of the repository that must be protected according to the policy.
+type protectedBranch struct {
+	name      string
+	isDefault bool
+	template  *ProtectionTemplate
+	// templateName is the name of the template in the policy.
+	templateName string
+}
+
+func (branch protectedBranch) String() string {
+	if branch.isDefault {
+		return "default branch " + branch.name
+	} else {
+		return "branch " + branch.name
+	}
 }
 
 type BranchProtectionProblemHandler interface {
-	createBranchProtection(repo string, branch string, protection *github.ProtectionRequest)
-	updateProtection(repo string, branch string, protection *github.ProtectionRequest)
+	createBranchProtection(repo string, branch protectedBranch, protection *github.ProtectionRequest)
+	updateProtection(repo string, branch protectedBranch, protection *github.ProtectionRequest, differences []protectionDifference)
+	removeStaleChecks(repo string, branch protectedBranch, protection *github.ProtectionRequest, staleChecks []string)
 }
 
 type LogBranchProtectionProblemHandler struct {
 }
 
-func (logHandler LogBranchProtectionProblemHandler) createBranchProtection(repo string, branch string, protection *github.ProtectionRequest) {
-	fmt.Printf("exasol/%v does not have a branch protection rule for default branch %v. Use --fix to create it. This error can also happen if you don't have admin privileges on the repo.", repo, branch)
+func (logHandler LogBranchProtectionProblemHandler) createBranchProtection(repo string, branch protectedBranch, protection *github.ProtectionRequest) {
+	fmt.Printf("exasol/%v does not have a branch protection rule for %v. Use --fix to create it. This error can also happen if you don't have admin privileges on the repo.", repo, branch)
 }
 
 type FixBranchProtectionProblemHandler struct {
 	client *github.Client
 }
 
-func (logHandler LogBranchProtectionProblemHandler) updateProtection(repo string, branch string, protection *github.ProtectionRequest) {
-	fmt.Printf("exasol/%v has a branch protection for default branch %v that is not compliant to our standards. Use --fix to update.\n", repo, branch)
+func (logHandler LogBranchProtectionProblemHandler) updateProtection(repo string, branch protectedBranch, protection *github.ProtectionRequest, differences []protectionDifference) {
+	fmt.Printf("exasol/%v has a branch protection for %v that is not compliant to our standards. Use --fix to update.\n", repo, branch)
+	for _, difference := range differences {
+		fmt.Printf("  - %v\n", difference)
+	}
+}
+
+func (logHandler LogBranchProtectionProblemHandler) removeStaleChecks(repo string, branch protectedBranch, protection *github.ProtectionRequest, staleChecks []string) {
+	for _, staleCheck := range staleChecks {
+		fmt.Printf("exasol/%v requires the status check '%v' for %v that no workflow produces anymore. Use --fix to remove it or add it to keepChecks of the policy.\n", repo, staleCheck, branch)
+	}
 }
 
-func (handler FixBranchProtectionProblemHandler) createBranchProtection(repo string, branch string, protection *github.ProtectionRequest) {
-	_, _, err := handler.client.Repositories.UpdateBranchProtection(context.Background(), "exasol", repo, branch, protection)
+func (handler FixBranchProtectionProblemHandler) createBranchProtection(repo string, branch protectedBranch, protection *github.ProtectionRequest) {
+	_, _, err := handler.client.Repositories.UpdateBranchProtection(context.Background(), "exasol", repo, branch.name, protection)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to create branch protection for exasol/%v/%v. Cause: %v", repo, branch, err.Error()))
+		panic(fmt.Sprintf("Failed to create branch protection for exasol/%v/%v. Cause: %v", repo, branch.name, err.Error()))
 	} else {
-		fmt.Printf("Sucessfully created branch protection for exasol/%v/%v.\n", repo, branch)
+		fmt.Printf("Sucessfully created branch protection for exasol/%v/%v.\n", repo, branch.name)
+	}
+	if branch.template.RequireSignedCommits {
+		handler.updateCommitSignatureProtection(repo, branch)
 	}
 }
 
-func (handler FixBranchProtectionProblemHandler) updateProtection(repo string, branch string, protection *github.ProtectionRequest) {
+func (handler FixBranchProtectionProblemHandler) updateProtection(repo string, branch protectedBranch, protection *github.ProtectionRequest, differences []protectionDifference) {
 	handler.createBranchProtection(repo, branch, protection)
+	if !branch.template.RequireSignedCommits && containsDifference(differences, requireSignedCommitsAttribute) {
+		handler.updateCommitSignatureProtection(repo, branch)
+	}
+}
+
+// updateCommitSignatureProtection enables or disables required commit signatures. They are managed by a separate
+// endpoint and not part of the protection request.
+func (handler FixBranchProtectionProblemHandler) updateCommitSignatureProtection(repo string, branch protectedBranch) {
+	var err error
+	if branch.template.RequireSignedCommits {
+		_, _, err = handler.client.Repositories.RequireSignaturesOnProtectedBranch(context.Background(), "exasol", repo, branch.name)
+	} else {
+		_, err = handler.client.Repositories.OptionalSignaturesOnProtectedBranch(context.Background(), "exasol", repo, branch.name)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Failed to update required commit signatures for exasol/%v/%v. Cause: %v", repo, branch.name, err.Error()))
+	}
+}
+
+func (handler FixBranchProtectionProblemHandler) removeStaleChecks(repo string, branch protectedBranch, protection *github.ProtectionRequest, staleChecks []string) {
+	for _, staleCheck := range staleChecks {
+		fmt.Printf("Removing stale required status check '%v' from exasol/%v/%v.\n", staleCheck, repo, branch.name)
+	}
+	removeChecksFromRequest(protection, staleChecks)
 }
 
 func (verifier BranchProtectionVerifier) CheckIfBranchProtectionIsApplied(fix bool) {
 	problemHandler := verifier.getProblemHandler(fix)
 	repo := verifier.getRepo()
-	defaultBranch := *repo.DefaultBranch
-	existingProtection, resp, _ := verifier.client.Repositories.GetBranchProtection(context.Background(), "exasol", verifier.repoName, defaultBranch)
-	protectionRequest := verifier.createProtectionRequest(verifier.isSonarRequired(repo.Language))
+	for _, branch := range verifier.getBranchesToProtect(repo) {
+		verifier.checkIfBranchProtectionIsAppliedToBranch(repo, branch, problemHandler)
+	}
+}
+
+func (verifier BranchProtectionVerifier) checkIfBranchProtectionIsAppliedToBranch(repo *github.Repository, branch protectedBranch, problemHandler BranchProtectionProblemHandler) {
+	existingProtection, resp, _ := verifier.client.Repositories.GetBranchProtection(context.Background(), "exasol", verifier.repoName, branch.name)
+	requiredChecks, complete := verifier.getRequiredChecksForBranch(branch)
+	verifier.verifyChecksAreReported(branch.name, requiredChecks)
+	protectionRequest := verifier.createProtectionRequest(branch, requiredChecks)
 	if resp.StatusCode == 404 {
-		problemHandler.createBranchProtection(verifier.repoName, defaultBranch, &protectionRequest)
+		problemHandler.createBranchProtection(verifier.repoName, branch, &protectionRequest)
 	} else {
-		if !(existingProtection.AllowForcePushes.Enabled == *protectionRequest.AllowForcePushes &&
-			existingProtection.EnforceAdmins.Enabled == protectionRequest.EnforceAdmins &&
-			verifier.checkIfPrReviewPolicyIsApplied(existingProtection.RequiredPullRequestReviews, protectionRequest.RequiredPullRequestReviews) &&
-			verifier.checkIfStatusCheckPolicyIsApplied(existingProtection.RequiredStatusChecks, protectionRequest.RequiredStatusChecks) &&
-			verifier.checkIfBranchRestrictionsAreApplied(existingProtection.Restrictions, protectionRequest.Restrictions)) {
-			verifier.addExistingChecksToRequest(existingProtection, protectionRequest)
-			problemHandler.updateProtection(verifier.repoName, defaultBranch, &protectionRequest)
+		staleChecks := verifier.findStaleChecks(existingProtection, &protectionRequest, branch)
+		if !complete {
+			reportUnverifiableChecks(verifier.repoName, branch.name, staleChecks, verifier.report)
+			staleChecks = nil
 		}
+		differences := diffBranchProtection(existingProtection, &protectionRequest, branch.template.RequireSignedCommits, staleChecks)
+		if len(differences) > 0 {
+			for _, difference := range differences {
+				verifier.report.add(difference.toFinding(verifier.repoName, branch.name))
+			}
+			verifier.addExistingChecksToRequest(existingProtection, &protectionRequest)
+			problemHandler.removeStaleChecks(verifier.repoName, branch, &protectionRequest, staleChecks)
+			problemHandler.updateProtection(verifier.repoName, branch, &protectionRequest, differences)
+		}
+	}
+}
+
+func (verifier BranchProtectionVerifier) getPolicy() *BranchProtectionPolicy {
+	if verifier.policy == nil {
+		defaultPolicy := defaultBranchProtectionPolicy()
+		return &defaultPolicy
+	}
+	return verifier.policy
+}
+
+// getBranchesToProtect returns the branches of the repository that match a pattern of the branch protection policy.
+func (verifier BranchProtectionVerifier) getBranchesToProtect(repo *github.Repository) []protectedBranch {
+	policy := verifier.getPolicy()
+	defaultBranch := repo.GetDefaultBranch()
+	var branchNames []string
+	if policy.onlyProtectsDefaultBranch() {
+		branchNames = []string{defaultBranch}
+	} else {
+		branchNames = verifier.listBranches()
+	}
+	var result []protectedBranch
+	for _, branchName := range branchNames {
+		isDefault := branchName == defaultBranch
+		rule := policy.findRuleForBranch(branchName, isDefault)
+		if rule != nil {
+			template := policy.findTemplateForBranch(branchName, isDefault)
+			result = append(result, protectedBranch{name: branchName, isDefault: isDefault, template: template, templateName: rule.Template})
+		}
+	}
+	return result
+}
+
+func (verifier BranchProtectionVerifier) listBranches() []string {
+	var result []string
+	options := &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		branches, response, err := verifier.client.Repositories.ListBranches(context.Background(), "exasol", verifier.repoName, options)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to list branches of repository exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+		}
+		for _, branch := range branches {
+			result = append(result, branch.GetName())
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
 	}
+	return result
 }
 
-func (verifier BranchProtectionVerifier) isSonarRequired(language *string) bool {
-	return language != nil && (*language == "Scala" || *language == "Java" || *language == "Go")
+// findSonarEvidence returns the evidence that the branch requires the SonarCloud check or "" if it does not.
+func (verifier BranchProtectionVerifier) findSonarEvidence(branch string) string {
+	checkName := verifier.getPolicy().SonarCheckName
+	if checkName == "" {
+		return ""
+	}
+	reportedChecksVerifier := ReportedChecksVerifier{repoName: verifier.repoName, client: verifier.client, commitCount: sonarCheckRunHistory}
+	detector := sonarDetector{source: verifier.getContentSource(branch), checkName: checkName, getRecentCheckRuns: func() []string {
+		return reportedChecksVerifier.getRecentCheckRunNames(branch)
+	}}
+	return detector.findEvidence()
 }
 
 func (verifier BranchProtectionVerifier) getRepo() *github.Repository {
@@ -79,33 +232,72 @@ func (verifier BranchProtectionVerifier) getRepo() *github.Repository {
 	return repo
 }
 
-func (verifier BranchProtectionVerifier) addExistingChecksToRequest(existingProtection *github.Protection, protectionRequest github.ProtectionRequest) {
-	if existingProtection == nil || existingProtection.RequiredStatusChecks == nil || existingProtection.RequiredStatusChecks.Contexts == nil || len(existingProtection.RequiredStatusChecks.Contexts) == 0 {
-		return
+// findStaleChecks returns the required checks of the existing protection that are neither produced by a workflow
+// nor listed in the keep list of the policy.
+func (verifier BranchProtectionVerifier) findStaleChecks(existingProtection *github.Protection, protectionRequest *github.ProtectionRequest, branch protectedBranch) []string {
+	if !branch.template.RequireStatusChecks || existingProtection == nil || existingProtection.RequiredStatusChecks == nil {
+		return nil
 	}
-	if protectionRequest.RequiredStatusChecks == nil {
-		protectionRequest.RequiredStatusChecks = &github.RequiredStatusChecks{
-			Strict:   true,
-			Contexts: []string{},
+	var requiredChecks []string
+	if protectionRequest.RequiredStatusChecks != nil {
+		requiredChecks = getStatusCheckContexts(protectionRequest.RequiredStatusChecks.Checks)
+	}
+	var staleChecks []string
+	for _, existingCheck := range getStatusCheckContexts(getExistingStatusChecks(existingProtection.RequiredStatusChecks)) {
+		if !verifier.containsValue(requiredChecks, existingCheck) && !matchesAnyPattern(verifier.getPolicy().KeepChecks, existingCheck) {
+			staleChecks = append(staleChecks, existingCheck)
 		}
 	}
-	for _, existingCheck := range existingProtection.RequiredStatusChecks.Contexts {
-		if !verifier.containsValue(protectionRequest.RequiredStatusChecks.Contexts, existingCheck) {
-			protectionRequest.RequiredStatusChecks.Contexts = append(protectionRequest.RequiredStatusChecks.Contexts, existingCheck)
+	return staleChecks
+}
+
+// reportUnverifiableChecks reports the existing checks that no derived check matches while the derivation is incomplete.
+// They are kept since they may be produced by a workflow that github-keeper could not resolve.
+func reportUnverifiableChecks(repo string, branch string, checks []string, report *findingsReport) {
+	for _, check := range checks {
+		finding := Finding{Kind: unverifiableCheckFinding, Repo: repo, Branch: branch,
+			Message: fmt.Sprintf("The required check '%v' is not produced by any workflow that github-keeper could resolve. It is kept because some workflows could not be parsed or contain unresolvable matrices.", check)}
+		printFindingWarning(finding)
+		report.add(finding)
+	}
+}
+
+// addExistingChecksToRequest adds all existing checks to the request. They keep their app binding. Stale checks are
+// removed by the problem handler.
+func (verifier BranchProtectionVerifier) addExistingChecksToRequest(existingProtection *github.Protection, protectionRequest *github.ProtectionRequest) {
+	if existingProtection == nil {
+		return
+	}
+	for _, existingCheck := range getExistingStatusChecks(existingProtection.RequiredStatusChecks) {
+		if protectionRequest.RequiredStatusChecks == nil {
+			protectionRequest.RequiredStatusChecks = &github.RequiredStatusChecks{
+				Strict: true,
+				Checks: []*github.RequiredStatusCheck{},
+			}
+		}
+		if findStatusCheck(protectionRequest.RequiredStatusChecks.Checks, existingCheck.Context) == nil {
+			protectionRequest.RequiredStatusChecks.Checks = append(protectionRequest.RequiredStatusChecks.Checks, &github.RequiredStatusCheck{Context: existingCheck.Context, AppID: existingCheck.AppID})
 		}
 	}
 }
 
-func (verifier BranchProtectionVerifier) checkIfStatusCheckPolicyIsApplied(existing *github.RequiredStatusChecks, request *github.RequiredStatusChecks) bool {
-	if existing == nil || request == nil {
-		return false
+// removeChecksFromRequest removes the given checks from the request. Without remaining checks the request doesn't require
+// status checks.
+func removeChecksFromRequest(protectionRequest *github.ProtectionRequest, checks []string) {
+	if protectionRequest.RequiredStatusChecks == nil {
+		return
 	}
-	for _, requiredCheck := range request.Contexts {
-		if !verifier.containsValue(existing.Contexts, requiredCheck) {
-			return false
+	var remainingChecks []*github.RequiredStatusCheck
+	for _, check := range protectionRequest.RequiredStatusChecks.Checks {
+		if !containsString(checks, check.Context) {
+			remainingChecks = append(remainingChecks, check)
 		}
 	}
-	return existing.Strict == request.Strict
+	if len(remainingChecks) == 0 {
+		protectionRequest.RequiredStatusChecks = nil
+	} else {
+		protectionRequest.RequiredStatusChecks.Checks = remainingChecks
+	}
 }
 
 func (verifier BranchProtectionVerifier) containsValue(values []string, value string) bool {
@@ -117,40 +309,30 @@ func (verifier BranchProtectionVerifier) containsValue(values []string, value st
 	return false
 }
 
-func (verifier BranchProtectionVerifier) checkIfPrReviewPolicyIsApplied(existing *github.PullRequestReviewsEnforcement, request *github.PullRequestReviewsEnforcementRequest) bool {
-	return existing != nil && request != nil &&
-		existing.RequiredApprovingReviewCount >= request.RequiredApprovingReviewCount &&
-		existing.DismissStaleReviews == request.DismissStaleReviews &&
-		existing.RequireCodeOwnerReviews == request.RequireCodeOwnerReviews
-}
-
 func (verifier BranchProtectionVerifier) checkIfBranchRestrictionsAreApplied(existing *github.BranchRestrictions, request *github.BranchRestrictionsRequest) bool {
-	return existing != nil && request != nil &&
-		stringSlicesEqualIgnoringOrder(getTeamNames(existing.Teams), request.Teams) &&
-		stringSlicesEqualIgnoringOrder(getUserNames(existing.Users), request.Users) &&
-		stringSlicesEqualIgnoringOrder(getAppNames(existing.Apps), request.Apps)
+	return existing != nil && request != nil && len(diffRestrictions(existing, request)) == 0
 }
 
-func getTeamNames(teams []*github.Team) []string {
+func getUserLogins(users []*github.User) []string {
 	var result []string
-	for _, team := range teams {
-		result = append(result, *team.Name)
+	for _, user := range users {
+		result = append(result, user.GetLogin())
 	}
 	return result
 }
 
-func getUserNames(users []*github.User) []string {
+func getTeamSlugs(teams []*github.Team) []string {
 	var result []string
-	for _, user := range users {
-		result = append(result, *user.Name)
+	for _, team := range teams {
+		result = append(result, team.GetSlug())
 	}
 	return result
 }
 
-func getAppNames(apps []*github.App) []string {
+func getAppSlugs(apps []*github.App) []string {
 	var result []string
 	for _, app := range apps {
-		result = append(result, *app.Name)
+		result = append(result, app.GetSlug())
 	}
 	return result
 }
@@ -165,99 +347,176 @@ func (verifier BranchProtectionVerifier) getProblemHandler(fix bool) BranchProte
 	return problemHandler
 }
 
-func (verifier BranchProtectionVerifier) createProtectionRequest(requireSonar bool) github.ProtectionRequest {
-	allowForcePushes := false
-	requiredChecks, err := verifier.getRequiredChecks(requireSonar)
+func (verifier BranchProtectionVerifier) verifyChecksAreReported(branch string, requiredChecks []requiredCheck) {
+	if verifier.reportedChecksHistory > 0 && len(requiredChecks) > 0 {
+		reportedChecksVerifier := ReportedChecksVerifier{repoName: verifier.repoName, client: verifier.client, commitCount: verifier.reportedChecksHistory, report: verifier.report}
+		reportedChecksVerifier.VerifyRequiredChecksAreReported(branch, requiredChecks)
+	}
+}
+
+// getRequiredChecksForBranch returns the checks that the protection template of the branch requires and if they are
+// complete. They are incomplete if a workflow could not be parsed or contains a matrix that can't be resolved.
+func (verifier BranchProtectionVerifier) getRequiredChecksForBranch(branch protectedBranch) ([]requiredCheck, bool) {
+	if !branch.template.RequireStatusChecks {
+		return nil, true
+	}
+	checks, complete, err := verifier.getRequiredChecksWithOrigin(branch.name)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to get required checks for repository %v. Cause: %v", verifier.repoName, err.Error()))
 	}
+	return checks, complete
+}
 
+func (verifier BranchProtectionVerifier) createProtectionRequest(branch protectedBranch, requiredChecks []requiredCheck) github.ProtectionRequest {
+	template := branch.template
+	allowForcePushes := template.AllowForcePushes
+	allowDeletions := template.AllowDeletions
+	requireLinearHistory := template.RequireLinearHistory
+	requireConversationResolution := template.RequireConversationResolution
+	blockCreations := template.BlockCreations
+	lockBranch := template.LockBranch
 	return github.ProtectionRequest{
 		RequiredStatusChecks: createRequiredStatusChecks(requiredChecks),
 		RequiredPullRequestReviews: &github.PullRequestReviewsEnforcementRequest{
-			DismissStaleReviews:          true,
-			RequireCodeOwnerReviews:      true,
-			RequiredApprovingReviewCount: 1,
+			DismissStaleReviews:                template.DismissStaleReviews,
+			RequireCodeOwnerReviews:            template.RequireCodeOwnerReviews,
+			RequiredApprovingReviewCount:       template.RequiredApprovingReviewCount,
+			BypassPullRequestAllowancesRequest: createBypassPullRequestAllowancesRequest(template.BypassPullRequestAllowances),
+			DismissalRestrictionsRequest:       createDismissalRestrictionsRequest(template.DismissalRestrictions),
 		},
-		EnforceAdmins: true,
+		EnforceAdmins: template.EnforceAdmins,
 		Restrictions: &github.BranchRestrictionsRequest{
 			Teams: []string{},
 			Users: []string{},
 			Apps:  []string{},
 		},
-		AllowForcePushes: &allowForcePushes,
+		AllowForcePushes:               &allowForcePushes,
+		AllowDeletions:                 &allowDeletions,
+		RequireLinearHistory:           &requireLinearHistory,
+		RequiredConversationResolution: &requireConversationResolution,
+		BlockCreations:                 &blockCreations,
+		LockBranch:                     &lockBranch,
+	}
+}
+
+func createBypassPullRequestAllowancesRequest(actors ProtectionActors) *github.BypassPullRequestAllowancesRequest {
+	if actors.isEmpty() {
+		return nil
+	}
+	return &github.BypassPullRequestAllowancesRequest{Users: nonNilList(actors.Users), Teams: nonNilList(actors.Teams), Apps: nonNilList(actors.Apps)}
+}
+
+// createDismissalRestrictionsRequest creates the dismissal restrictions. Without actors the restrictions are disabled.
+func createDismissalRestrictionsRequest(actors ProtectionActors) *github.DismissalRestrictionsRequest {
+	if actors.isEmpty() {
+		return nil
+	}
+	users := nonNilList(actors.Users)
+	teams := nonNilList(actors.Teams)
+	apps := nonNilList(actors.Apps)
+	return &github.DismissalRestrictionsRequest{Users: &users, Teams: &teams, Apps: &apps}
+}
+
+func nonNilList(values []string) []string {
+	if values == nil {
+		return []string{}
 	}
+	return values
 }
 
-func createRequiredStatusChecks(requiredChecks []string) *github.RequiredStatusChecks {
+func createRequiredStatusChecks(requiredChecks []requiredCheck) *github.RequiredStatusChecks {
 	if len(requiredChecks) > 0 {
 		return &github.RequiredStatusChecks{
-			Strict:   true,
-			Contexts: requiredChecks,
+			Strict: true,
+			Checks: toRequiredStatusChecks(requiredChecks),
 		}
 	} else {
 		return nil
 	}
 }
 
-func (verifier BranchProtectionVerifier) getRequiredChecks(requireSonar bool) ([]string, error) {
-	result := []string{}
-	_, directory, _, err := verifier.client.Repositories.GetContents(context.Background(), "exasol", verifier.repoName, ".github/workflows/", &github.RepositoryContentGetOptions{})
-	if err != nil {
-		errorMessage := err.Error()
-		if strings.Contains(errorMessage, "404 Not Found") {
-			directory = []*github.RepositoryContent{}
-		} else {
-			return nil, err
-		}
+func getCheckContexts(checks []requiredCheck) []string {
+	var result []string
+	for _, check := range checks {
+		result = append(result, check.context)
 	}
-	for _, fileDesc := range directory {
-		workflowFilePath := fileDesc.Path
-		if *fileDesc.Type == "dir" {
-			continue
-		}
-		requiredChecksForWorkflow, err := verifier.getChecksForWorkflow(workflowFilePath)
-		if err != nil {
-			return nil, err
-		}
-		for _, check := range requiredChecksForWorkflow {
-			if !verifier.containsValue(result, check) {
-				result = append(result, check)
-			}
-		}
+	return result
+}
+
+func (verifier BranchProtectionVerifier) getRequiredChecksWithOrigin(branch string) ([]requiredCheck, bool, error) {
+	result, complete, err := getRequiredChecksFromWorkflows(verifier.getContentSource(branch), branch)
+	if err != nil {
+		return nil, false, err
 	}
-	if requireSonar {
-		result = append(result, "SonarCloud Code Analysis")
+	if evidence := verifier.findSonarEvidence(branch); evidence != "" {
+		result = append(result, newSonarCheck(verifier.getPolicy().SonarCheckName, verifier.getPolicy().SonarAppId, evidence))
 	}
-	return result, nil
+	return result, complete, nil
+}
+
+func (verifier BranchProtectionVerifier) getContentSource(branch string) repositoryContentSource {
+	return githubContentSource{client: verifier.client, repoName: verifier.repoName, branch: branch}
+}
+
+func (verifier BranchProtectionVerifier) getChecksForWorkflowContent(content string, fileName *string, branch string) []string {
+	checks, _ := getChecksForWorkflowContent(verifier.getContentSource(branch), content, *fileName, branch)
+	return getWorkflowCheckNames(checks)
 }
 
-func (verifier BranchProtectionVerifier) getChecksForWorkflow(workflowFilePath *string) ([]string, error) {
-	content, err := verifier.downloadFile(*workflowFilePath)
+// getRequiredChecksFromWorkflows returns the checks of all workflows of the source that are reported for every pull
+// request to the given branch and if all workflows could be resolved completely.
+func getRequiredChecksFromWorkflows(source repositoryContentSource, branch string) ([]requiredCheck, bool, error) {
+	var result []requiredCheck
+	workflowFiles, err := source.listWorkflowFiles()
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	complete := true
+	for _, workflowFilePath := range workflowFiles {
+		content, err := source.readFile(workflowFilePath)
+		if err != nil {
+			return nil, false, err
+		}
+		checks, workflowComplete := getChecksForWorkflowContent(source, content, workflowFilePath, branch)
+		complete = complete && workflowComplete
+		for _, check := range checks {
+			if !containsString(getCheckContexts(result), check.name) {
+				result = append(result, requiredCheck{context: check.name, origin: "workflow " + workflowFilePath, job: check.jobKey, matrix: check.matrix, appId: githubActionsAppId})
+			}
+		}
 	}
-	return verifier.getChecksForWorkflowContent(content, workflowFilePath), nil
+	return result, complete, nil
 }
 
-func (verifier BranchProtectionVerifier) getChecksForWorkflowContent(content string, fileName *string) []string {
-	fileUrl := fmt.Sprintf("https://github.com/exasol/%s/blob/%s/%s", verifier.repoName, verifier.getRepo().GetDefaultBranch(), *fileName)
-	workflow, err := WorkflowDefinitionParser{}.ParseWorkflowDefinition(content)
+// getChecksForWorkflowContent returns the checks of the workflow and false if the workflow could not be parsed or contains
+// a matrix or reusable workflow that can't be resolved.
+func getChecksForWorkflowContent(source repositoryContentSource, content string, fileName string, branch string) ([]workflowCheck, bool) {
+	fileUrl := source.getFileUrl(fileName)
+	workflow, err := WorkflowDefinitionParser{loader: source}.ParseWorkflowDefinition(content)
 	if err != nil {
 		handleParseError(err, fileUrl)
-		return nil
+		return nil, false
+	}
+	reportsChecks, reason := workflow.Trigger.getReportingDecision(branch)
+	if reason != "" {
+		printFindingWarning(Finding{Kind: conditionalTriggerFinding, File: fileUrl, Message: fmt.Sprintf("The checks of this workflow are not required because %v.", reason)})
 	}
-	hasWorkflowPushOrPrTrigger := checkIfProtectionNeeded(workflow.Trigger)
-	if hasWorkflowPushOrPrTrigger {
-		jobNames, err := workflow.GetJobNames()
+	if reportsChecks {
+		workflow.reportingEvents = workflow.Trigger.getReportingEvents(branch)
+		checks, findings, err := workflow.getChecksAndFindings()
 		if err != nil {
 			handleParseError(err, fileUrl)
-			return nil
-		} else {
-			return jobNames
+			return nil, false
 		}
+		complete := true
+		for _, finding := range findings {
+			finding.File = fileUrl
+			printFindingWarning(finding)
+			complete = complete && finding.Kind != unresolvableMatrixFinding && finding.Kind != unresolvableReusableWorkflowFinding
+		}
+		return checks, complete
 	}
-	return nil
+	return nil, true
 }
 
 func handleParseError(err error, fileUrl string) {
@@ -266,23 +525,11 @@ func handleParseError(err error, fileUrl string) {
 		fmt.Printf("%vValidation Error for '%v': %v %v\n", consoleColorRed, fileUrl, err.Error(), consoleColorReset)
 		os.Exit(1)
 	default:
-		printParseFailedWarning(fileUrl)
+		printParseFailedWarning(fileUrl, err)
 		return
 	}
 }
 
-func printParseFailedWarning(fileUrl string) {
-	fmt.Printf("%vWarning: Failed to parse workflow definition '%v'. Probably you use some advanced matrix build features there. Github-keeper will not add the checks from this workflow to the branch protection. Please add them manually. %v\n", consoleColorYellow, fileUrl, consoleColorReset)
-}
-
-func checkIfProtectionNeeded(triggers *TriggerDefinition) bool {
-	return triggers.TriggerOnPr || triggers.TriggerOnPushToAnyBranch
-}
-
-func (verifier BranchProtectionVerifier) downloadFile(path string) (string, error) {
-	workflowFile, _, _, err := verifier.client.Repositories.GetContents(context.Background(), "exasol", verifier.repoName, path, &github.RepositoryContentGetOptions{})
-	if err != nil {
-		return "", err
-	}
-	return workflowFile.GetContent()
+func printParseFailedWarning(fileUrl string, cause error) {
+	fmt.Printf("%vWarning: Failed to parse workflow definition '%v': %v. Github-keeper will not add the checks from this workflow to the branch protection. Please add them manually. %v\n", consoleColorYellow, fileUrl, cause.Error(), consoleColorReset)
 }
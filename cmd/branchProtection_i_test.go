@@ -7,7 +7,7 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/google/go-github/v43/github"
+	"github.com/google/go-github/v57/github"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -72,13 +72,32 @@ func (suite *BranchProtectionSuite) TestBranchProtectionUpdatePreserversExisting
 	}
 	_, _, err := suite.githubClient.Repositories.UpdateBranchProtection(context.Background(), suite.testOrg, suite.testRepo, suite.testDefaultBranch, &request)
 	suite.NoError(err)
-	verifier := BranchProtectionVerifier{repoName: suite.testRepo, client: suite.githubClient}
+	policy := defaultBranchProtectionPolicy()
+	policy.KeepChecks = []string{"myAdditionalCheck"}
+	verifier := BranchProtectionVerifier{repoName: suite.testRepo, client: suite.githubClient, policy: &policy}
 	verifier.CheckIfBranchProtectionIsApplied(true)
 	protection, _, err := suite.githubClient.Repositories.GetBranchProtection(context.Background(), suite.testOrg, suite.testRepo, "master")
 	suite.NoError(err)
 	suite.Contains(protection.RequiredStatusChecks.Contexts, "myAdditionalCheck")
 }
 
+func (suite *BranchProtectionSuite) TestBranchProtectionUpdateRemovesStaleChecks() {
+	suite.cleanup()
+	defer suite.cleanup()
+	request := github.ProtectionRequest{
+		RequiredStatusChecks: &github.RequiredStatusChecks{
+			Contexts: []string{"myStaleCheck"},
+		},
+	}
+	_, _, err := suite.githubClient.Repositories.UpdateBranchProtection(context.Background(), suite.testOrg, suite.testRepo, suite.testDefaultBranch, &request)
+	suite.NoError(err)
+	verifier := BranchProtectionVerifier{repoName: suite.testRepo, client: suite.githubClient}
+	verifier.CheckIfBranchProtectionIsApplied(true)
+	protection, _, err := suite.githubClient.Repositories.GetBranchProtection(context.Background(), suite.testOrg, suite.testRepo, "master")
+	suite.NoError(err)
+	suite.NotContains(protection.RequiredStatusChecks.Contexts, "myStaleCheck")
+}
+
 func (suite *BranchProtectionSuite) TestBranchProtectionIncomplete() {
 	suite.cleanup()
 	defer suite.cleanup()
@@ -138,7 +157,7 @@ jobs:
       matrix:
         test-path: ${{fromJson(needs.prep-testbed.outputs.matrix)}}
     runs-on: ubuntu-latest
-`, &fileName)
+`, &fileName, suite.testDefaultBranch)
 	})
 	suite.Contains(output, "\x1b[33mWarning:")
 }
@@ -161,7 +180,7 @@ jobs:
          - id: 2
            num: 20
     runs-on: ubuntu-latest
-`, &fileName)
+`, &fileName, suite.testDefaultBranch)
 	} else {
 		cmd := exec.Command(os.Args[0], "-test.run=TestBranchProtectionSuite/TestGetChecksForWorkflowContentWithValidationError")
 		cmd.Env = append(os.Environ(), "RUN_TEST=1")
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/suite"
+)
+
+type BranchProtectionUnitSuite struct {
+	suite.Suite
+}
+
+func TestBranchProtectionUnitSuite(t *testing.T) {
+	suite.Run(t, new(BranchProtectionUnitSuite))
+}
+
+func (suite *BranchProtectionUnitSuite) getDefaultBranch() protectedBranch {
+	policy := defaultBranchProtectionPolicy()
+	return protectedBranch{name: "main", isDefault: true, template: policy.findTemplateForBranch("main", true)}
+}
+
+// workflowChecks creates required checks that are reported by workflow jobs.
+func workflowChecks(contexts ...string) []requiredCheck {
+	var result []requiredCheck
+	for _, context := range contexts {
+		result = append(result, requiredCheck{context: context, origin: "workflow", appId: githubActionsAppId})
+	}
+	return result
+}
+
+func (suite *BranchProtectionUnitSuite) createProtection(contexts ...string) *github.Protection {
+	return &github.Protection{RequiredStatusChecks: &github.RequiredStatusChecks{Strict: true, Contexts: contexts}}
+}
+
+func (suite *BranchProtectionUnitSuite) TestFindStaleChecks() {
+	verifier := BranchProtectionVerifier{}
+	request := github.ProtectionRequest{RequiredStatusChecks: createRequiredStatusChecks(workflowChecks("build"))}
+	staleChecks := verifier.findStaleChecks(suite.createProtection("build", "removed-job"), &request, suite.getDefaultBranch())
+	suite.Equal([]string{"removed-job"}, staleChecks)
+}
+
+func (suite *BranchProtectionUnitSuite) TestFindStaleChecksIgnoresKeptChecks() {
+	policy := defaultBranchProtectionPolicy()
+	policy.KeepChecks = []string{"external/*"}
+	verifier := BranchProtectionVerifier{policy: &policy}
+	request := github.ProtectionRequest{RequiredStatusChecks: createRequiredStatusChecks(workflowChecks("build"))}
+	staleChecks := verifier.findStaleChecks(suite.createProtection("build", "external/scanner"), &request, suite.getDefaultBranch())
+	suite.Empty(staleChecks)
+}
+
+func (suite *BranchProtectionUnitSuite) TestFindStaleChecksWithoutManagedChecks() {
+	verifier := BranchProtectionVerifier{}
+	branch := suite.getDefaultBranch()
+	branch.template.RequireStatusChecks = false
+	staleChecks := verifier.findStaleChecks(suite.createProtection("manual-check"), &github.ProtectionRequest{}, branch)
+	suite.Empty(staleChecks)
+}
+
+func (suite *BranchProtectionUnitSuite) TestAddExistingChecksToRequestKeepsAllChecks() {
+	verifier := BranchProtectionVerifier{}
+	request := github.ProtectionRequest{RequiredStatusChecks: createRequiredStatusChecks(workflowChecks("build"))}
+	verifier.addExistingChecksToRequest(suite.createProtection("build", "kept", "stale"), &request)
+	suite.Equal([]string{"build", "kept", "stale"}, getStatusCheckContexts(request.RequiredStatusChecks.Checks))
+}
+
+func (suite *BranchProtectionUnitSuite) TestAddExistingChecksToRequestWithoutRequiredChecks() {
+	verifier := BranchProtectionVerifier{}
+	request := github.ProtectionRequest{}
+	verifier.addExistingChecksToRequest(suite.createProtection("kept"), &request)
+	suite.Equal([]string{"kept"}, getStatusCheckContexts(request.RequiredStatusChecks.Checks))
+}
+
+func (suite *BranchProtectionUnitSuite) TestRemoveChecksFromRequest() {
+	request := github.ProtectionRequest{RequiredStatusChecks: createRequiredStatusChecks(workflowChecks("build", "stale"))}
+	removeChecksFromRequest(&request, []string{"stale"})
+	suite.Equal([]string{"build"}, getStatusCheckContexts(request.RequiredStatusChecks.Checks))
+}
+
+func (suite *BranchProtectionUnitSuite) TestRemoveAllChecksFromRequest() {
+	request := github.ProtectionRequest{RequiredStatusChecks: createRequiredStatusChecks(workflowChecks("stale"))}
+	removeChecksFromRequest(&request, []string{"stale"})
+	suite.Nil(request.RequiredStatusChecks)
+}
+
+// TestFixHandlerClearsChecksOfDeletedWorkflows covers a branch whose workflows were all deleted or renamed, so that no
+// check is derived and all existing checks are stale.
+func (suite *BranchProtectionUnitSuite) TestFixHandlerClearsChecksOfDeletedWorkflows() {
+	verifier := BranchProtectionVerifier{}
+	branch := suite.getDefaultBranch()
+	existing := suite.createProtection("old-job")
+	request := verifier.createProtectionRequest(branch, nil)
+	staleChecks := verifier.findStaleChecks(existing, &request, branch)
+	suite.Equal([]string{"old-job"}, staleChecks)
+	verifier.addExistingChecksToRequest(existing, &request)
+	FixBranchProtectionProblemHandler{}.removeStaleChecks("my-repo", branch, &request, staleChecks)
+	suite.Nil(request.RequiredStatusChecks)
+}
+
+func (suite *BranchProtectionUnitSuite) TestLogHandlerDoesNotRemoveStaleChecks() {
+	request := github.ProtectionRequest{RequiredStatusChecks: createRequiredStatusChecks(workflowChecks("build", "stale"))}
+	LogBranchProtectionProblemHandler{}.removeStaleChecks("my-repo", suite.getDefaultBranch(), &request, []string{"stale"})
+	suite.Equal([]string{"build", "stale"}, getStatusCheckContexts(request.RequiredStatusChecks.Checks))
+}
+
+func (suite *BranchProtectionUnitSuite) TestCreateProtectionRequestWithReviewAllowances() {
+	branch := suite.getDefaultBranch()
+	branch.template.BypassPullRequestAllowances = ProtectionActors{Apps: []string{"dependabot"}}
+	branch.template.DismissalRestrictions = ProtectionActors{Teams: []string{"maintainers"}}
+	request := BranchProtectionVerifier{}.createProtectionRequest(branch, nil)
+	reviews := request.RequiredPullRequestReviews
+	suite.Equal(&github.BypassPullRequestAllowancesRequest{Users: []string{}, Teams: []string{}, Apps: []string{"dependabot"}}, reviews.BypassPullRequestAllowancesRequest)
+	suite.Equal([]string{"maintainers"}, *reviews.DismissalRestrictionsRequest.Teams)
+	suite.Equal([]string{}, *reviews.DismissalRestrictionsRequest.Users)
+}
+
+func (suite *BranchProtectionUnitSuite) TestCreateProtectionRequestWithoutReviewAllowances() {
+	request := BranchProtectionVerifier{}.createProtectionRequest(suite.getDefaultBranch(), nil)
+	suite.Nil(request.RequiredPullRequestReviews.BypassPullRequestAllowancesRequest)
+	suite.Nil(request.RequiredPullRequestReviews.DismissalRestrictionsRequest)
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+const missingCodeOwnersFinding = "missing-codeowners"
+const invalidCodeOwnersFinding = "invalid-codeowners"
+const unknownCodeOwnerFinding = "unknown-code-owner"
+const codeOwnerWithoutWriteAccessFinding = "code-owner-without-write-access"
+
+// codeOwnersLocations are the locations where GitHub looks for the CODEOWNERS file, in the order GitHub uses them.
+var codeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+const codeOwnersBranch = "github-keeper/codeowners"
+
+var codeOwnerUserPattern = regexp.MustCompile(`^@[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?$`)
+var codeOwnerTeamPattern = regexp.MustCompile(`^@([A-Za-z0-9-]+)/([A-Za-z0-9._-]+)$`)
+var codeOwnerEmailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// codeOwnersRule is a line of a CODEOWNERS file that assigns owners to the files matching the pattern.
+type codeOwnersRule struct {
+	line    int
+	pattern string
+	owners  []string
+}
+
+type ownerAccess int
+
+const (
+	ownerNotFound ownerAccess = iota
+	ownerWithoutWriteAccess
+	ownerWithWriteAccess
+)
+
+// codeOwnerAccess looks up if users and teams exist and have write access to the repository.
+type codeOwnerAccess interface {
+	getUserAccess(login string) ownerAccess
+	getTeamAccess(slug string) ownerAccess
+}
+
+// CodeOwnersVerifier verifies that repositories that require code owner reviews have a CODEOWNERS file whose owners
+// can approve pull requests. Otherwise, pull requests can never be approved.
+type CodeOwnersVerifier struct {
+	client   *github.Client
+	repoName string
+	policy   *Policy
+	// report collects the findings. It may be nil.
+	report *findingsReport
+	access codeOwnerAccess
+}
+
+func (verifier CodeOwnersVerifier) VerifyCodeOwners(fix bool) {
+	repo, _, err := verifier.client.Repositories.Get(context.Background(), "exasol", verifier.repoName)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get repository exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+	}
+	template := verifier.policy.BranchProtection.findTemplateForBranch(repo.GetDefaultBranch(), true)
+	if template == nil || !template.RequireCodeOwnerReviews {
+		return
+	}
+	source := githubContentSource{client: verifier.client, repoName: verifier.repoName, branch: repo.GetDefaultBranch()}
+	if verifier.access == nil {
+		verifier.access = githubCodeOwnerAccess{client: verifier.client, repoName: verifier.repoName}
+	}
+	path, findings := verifier.verifyCodeOwnersOfSource(source)
+	for _, finding := range findings {
+		printFindingWarning(finding)
+	}
+	verifier.report.add(findings...)
+	if fix && len(findings) > 0 {
+		verifier.proposeCodeOwners(path, findings)
+	}
+}
+
+// verifyCodeOwnersOfSource locates the CODEOWNERS file and verifies it. It returns the path of the file, or the
+// preferred location if there is none, and the findings.
+func (verifier CodeOwnersVerifier) verifyCodeOwnersOfSource(source repositoryContentSource) (string, []Finding) {
+	path, content, found := findCodeOwnersFile(source)
+	if !found {
+		return path, []Finding{{Kind: missingCodeOwnersFinding, Repo: verifier.repoName, File: path,
+			Message: "the repository requires code owner reviews but has no CODEOWNERS file"}}
+	}
+	rules, problems := parseCodeOwners(content)
+	var findings []Finding
+	for _, problem := range problems {
+		findings = append(findings, Finding{Kind: invalidCodeOwnersFinding, Repo: verifier.repoName, File: path, Message: problem})
+	}
+	if len(rules) == 0 && len(problems) == 0 {
+		findings = append(findings, Finding{Kind: invalidCodeOwnersFinding, Repo: verifier.repoName, File: path, Message: "the file does not assign any code owners"})
+	}
+	return path, append(findings, verifier.verifyOwners(path, rules)...)
+}
+
+func findCodeOwnersFile(source repositoryContentSource) (string, string, bool) {
+	for _, location := range codeOwnersLocations {
+		if content, found := readOptionalContent(source, location); found {
+			return location, content, true
+		}
+	}
+	return codeOwnersLocations[0], "", false
+}
+
+// parseCodeOwners reads the rules of a CODEOWNERS file and returns descriptions of the lines that GitHub can't use.
+func parseCodeOwners(content string) ([]codeOwnersRule, []string) {
+	var rules []codeOwnersRule
+	var problems []string
+	for index, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(removeCodeOwnersComment(line))
+		if len(fields) == 0 {
+			continue
+		}
+		rule := codeOwnersRule{line: index + 1, pattern: fields[0]}
+		if strings.HasPrefix(rule.pattern, "!") || strings.ContainsAny(rule.pattern, "[]") {
+			problems = append(problems, fmt.Sprintf("line %d: the pattern '%v' uses negation or character ranges that CODEOWNERS does not support", rule.line, rule.pattern))
+			continue
+		}
+		for _, owner := range fields[1:] {
+			if isValidCodeOwner(owner) {
+				rule.owners = append(rule.owners, owner)
+			} else {
+				problems = append(problems, fmt.Sprintf("line %d: '%v' is not a valid owner. Use @user, @exasol/team or an email address", rule.line, owner))
+			}
+		}
+		if len(rule.owners) > 0 {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, problems
+}
+
+// removeCodeOwnersComment removes a comment that starts with a # at the beginning of the line or after whitespace.
+func removeCodeOwnersComment(line string) string {
+	for index, character := range line {
+		if character == '#' && (index == 0 || line[index-1] == ' ' || line[index-1] == '\t') {
+			return line[:index]
+		}
+	}
+	return line
+}
+
+func isValidCodeOwner(owner string) bool {
+	if match := codeOwnerTeamPattern.FindStringSubmatch(owner); match != nil {
+		return strings.EqualFold(match[1], "exasol")
+	}
+	return codeOwnerUserPattern.MatchString(owner) || codeOwnerEmailPattern.MatchString(owner)
+}
+
+// verifyOwners checks that the users and teams of the rules exist and have write access. Email addresses can't be
+// verified.
+func (verifier CodeOwnersVerifier) verifyOwners(path string, rules []codeOwnersRule) []Finding {
+	var findings []Finding
+	var verifiedOwners []string
+	for _, rule := range rules {
+		for _, owner := range rule.owners {
+			if containsString(verifiedOwners, owner) || !strings.HasPrefix(owner, "@") {
+				continue
+			}
+			verifiedOwners = append(verifiedOwners, owner)
+			var access ownerAccess
+			if match := codeOwnerTeamPattern.FindStringSubmatch(owner); match != nil {
+				access = verifier.access.getTeamAccess(match[2])
+			} else {
+				access = verifier.access.getUserAccess(strings.TrimPrefix(owner, "@"))
+			}
+			switch access {
+			case ownerNotFound:
+				findings = append(findings, Finding{Kind: unknownCodeOwnerFinding, Repo: verifier.repoName, File: path,
+					Message: fmt.Sprintf("line %d: the owner %v does not exist", rule.line, owner)})
+			case ownerWithoutWriteAccess:
+				findings = append(findings, Finding{Kind: codeOwnerWithoutWriteAccessFinding, Repo: verifier.repoName, File: path,
+					Message: fmt.Sprintf("line %d: the owner %v has no write access, so its reviews don't count", rule.line, owner)})
+			}
+		}
+	}
+	return findings
+}
+
+func (verifier CodeOwnersVerifier) proposeCodeOwners(path string, findings []Finding) {
+	template := verifier.policy.CodeOwners.Template
+	if template == "" {
+		fmt.Printf("Can't propose a CODEOWNERS file for exasol/%v since the policy does not define codeOwners.template.\n", verifier.repoName)
+		return
+	}
+	body := "github-keeper found the following problems:\n\n"
+	for _, finding := range findings {
+		body += "* " + finding.Message + "\n"
+	}
+	FileProposer{client: verifier.client, repoName: verifier.repoName}.Propose(fileProposal{files: []proposedFile{{path: path, content: template}},
+		branch: codeOwnersBranch, title: "Update CODEOWNERS", body: body})
+}
+
+// githubCodeOwnerAccess looks up the access of users and teams of the exasol organization on GitHub.
+type githubCodeOwnerAccess struct {
+	client   *github.Client
+	repoName string
+}
+
+func (access githubCodeOwnerAccess) getUserAccess(login string) ownerAccess {
+	_, response, err := access.client.Users.Get(context.Background(), login)
+	if response != nil && response.StatusCode == 404 {
+		return ownerNotFound
+	} else if err != nil {
+		panic(fmt.Sprintf("Failed to get user %v. Cause: %v", login, err.Error()))
+	}
+	permission, response, err := access.client.Repositories.GetPermissionLevel(context.Background(), "exasol", access.repoName, login)
+	if response != nil && response.StatusCode == 404 {
+		return ownerWithoutWriteAccess
+	} else if err != nil {
+		panic(fmt.Sprintf("Failed to get permission of %v for exasol/%v. Cause: %v", login, access.repoName, err.Error()))
+	}
+	if permission.GetPermission() == "admin" || permission.GetPermission() == "write" {
+		return ownerWithWriteAccess
+	}
+	return ownerWithoutWriteAccess
+}
+
+func (access githubCodeOwnerAccess) getTeamAccess(slug string) ownerAccess {
+	_, response, err := access.client.Teams.GetTeamBySlug(context.Background(), "exasol", slug)
+	if response != nil && response.StatusCode == 404 {
+		return ownerNotFound
+	} else if err != nil {
+		panic(fmt.Sprintf("Failed to get team exasol/%v. Cause: %v", slug, err.Error()))
+	}
+	repo, response, err := access.client.Teams.IsTeamRepoBySlug(context.Background(), "exasol", slug, "exasol", access.repoName)
+	if response != nil && response.StatusCode == 404 {
+		return ownerWithoutWriteAccess
+	} else if err != nil {
+		panic(fmt.Sprintf("Failed to get permission of team exasol/%v for exasol/%v. Cause: %v", slug, access.repoName, err.Error()))
+	}
+	if repo.GetPermissions()["push"] || repo.GetPermissions()["admin"] {
+		return ownerWithWriteAccess
+	}
+	return ownerWithoutWriteAccess
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CodeOwnersSuite struct {
+	repositoryFixture
+}
+
+func TestCodeOwnersSuite(t *testing.T) {
+	suite.Run(t, new(CodeOwnersSuite))
+}
+
+// fakeCodeOwnerAccess returns the access of the users and teams in the map. Unknown owners don't exist.
+type fakeCodeOwnerAccess map[string]ownerAccess
+
+func (access fakeCodeOwnerAccess) getUserAccess(login string) ownerAccess {
+	return access[login]
+}
+
+func (access fakeCodeOwnerAccess) getTeamAccess(slug string) ownerAccess {
+	return access["team:"+slug]
+}
+
+func (suite *CodeOwnersSuite) verify() (string, []Finding) {
+	verifier := CodeOwnersVerifier{repoName: "my-repo", access: fakeCodeOwnerAccess{
+		"alice":             ownerWithWriteAccess,
+		"bob":               ownerWithoutWriteAccess,
+		"team:integration":  ownerWithWriteAccess,
+		"team:read-only-qa": ownerWithoutWriteAccess,
+	}}
+	return verifier.verifyCodeOwnersOfSource(suite.source())
+}
+
+func (suite *CodeOwnersSuite) TestMissingFile() {
+	path, findings := suite.verify()
+	suite.Equal(".github/CODEOWNERS", path)
+	suite.Equal([]Finding{{Kind: missingCodeOwnersFinding, Repo: "my-repo", File: ".github/CODEOWNERS",
+		Message: "the repository requires code owner reviews but has no CODEOWNERS file"}}, findings)
+}
+
+func (suite *CodeOwnersSuite) TestValidFile() {
+	suite.writeFile("docs/CODEOWNERS", "# Owners\n* @exasol/integration @alice # default owners\n/doc/ docs@exasol.com\n")
+	path, findings := suite.verify()
+	suite.Equal("docs/CODEOWNERS", path)
+	suite.Empty(findings)
+}
+
+func (suite *CodeOwnersSuite) TestGithubDirectoryHasPrecedence() {
+	suite.writeFile("CODEOWNERS", "* @unknown")
+	suite.writeFile(".github/CODEOWNERS", "* @alice")
+	path, findings := suite.verify()
+	suite.Equal(".github/CODEOWNERS", path)
+	suite.Empty(findings)
+}
+
+func (suite *CodeOwnersSuite) TestOwnersWithoutAccess() {
+	suite.writeFile("CODEOWNERS", "* @bob @exasol/read-only-qa\n*.go @unknown @bob\n")
+	_, findings := suite.verify()
+	suite.Equal([]Finding{
+		{Kind: codeOwnerWithoutWriteAccessFinding, Repo: "my-repo", File: "CODEOWNERS", Message: "line 1: the owner @bob has no write access, so its reviews don't count"},
+		{Kind: codeOwnerWithoutWriteAccessFinding, Repo: "my-repo", File: "CODEOWNERS", Message: "line 1: the owner @exasol/read-only-qa has no write access, so its reviews don't count"},
+		{Kind: unknownCodeOwnerFinding, Repo: "my-repo", File: "CODEOWNERS", Message: "line 2: the owner @unknown does not exist"},
+	}, findings)
+}
+
+func (suite *CodeOwnersSuite) TestInvalidLines() {
+	suite.writeFile("CODEOWNERS", "!*.md @alice\n* @other-org/team alice\n")
+	_, findings := suite.verify()
+	suite.Equal([]Finding{
+		{Kind: invalidCodeOwnersFinding, Repo: "my-repo", File: "CODEOWNERS", Message: "line 1: the pattern '!*.md' uses negation or character ranges that CODEOWNERS does not support"},
+		{Kind: invalidCodeOwnersFinding, Repo: "my-repo", File: "CODEOWNERS", Message: "line 2: '@other-org/team' is not a valid owner. Use @user, @exasol/team or an email address"},
+		{Kind: invalidCodeOwnersFinding, Repo: "my-repo", File: "CODEOWNERS", Message: "line 2: 'alice' is not a valid owner. Use @user, @exasol/team or an email address"},
+	}, findings)
+}
+
+func (suite *CodeOwnersSuite) TestFileWithoutOwners() {
+	suite.writeFile("CODEOWNERS", "# no owners yet\n")
+	_, findings := suite.verify()
+	suite.Equal([]Finding{{Kind: invalidCodeOwnersFinding, Repo: "my-repo", File: "CODEOWNERS", Message: "the file does not assign any code owners"}}, findings)
+}
+
+func (suite *CodeOwnersSuite) TestParseKeepsHashInsidePattern() {
+	rules, problems := parseCodeOwners("docs/issue#1.md @alice")
+	suite.Empty(problems)
+	suite.Equal([]codeOwnersRule{{line: 1, pattern: "docs/issue#1.md", owners: []string{"@alice"}}}, rules)
+}
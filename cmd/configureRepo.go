@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 
+	"github.com/google/go-github/v57/github"
 	"github.com/spf13/cobra"
 )
 
@@ -23,20 +24,66 @@ var configureRepoCmd = &cobra.Command{
 			panic(fmt.Sprintf("Could not read parameter secrets: %v", err.Error()))
 		}
 		secrets := ReadSecretsFromYaml(secretsFile)
+		policy := readPolicyFromFlags(cmd)
+		reportedChecksHistory, err := cmd.Flags().GetInt("verify-reported-checks")
+		if err != nil {
+			panic(fmt.Sprintf("Could not read parameter verify-reported-checks: %v", err.Error()))
+		}
+		reportFile, err := cmd.Flags().GetString("report")
+		if err != nil {
+			panic(fmt.Sprintf("Could not read parameter report: %v", err.Error()))
+		}
+		var report *findingsReport
+		if reportFile != "" {
+			report = &findingsReport{}
+		}
 		org := "exasol"
 		for index, repo := range args {
 			fmt.Printf("\nRepo %d of %d: https://github.com/%v/%v\n", index+1, len(args), org, repo)
-			branchProtectionVerifier := BranchProtectionVerifier{client: client, repoName: repo}
-			branchProtectionVerifier.CheckIfBranchProtectionIsApplied(fix)
-			UnifyLabels(repo, client, fix)
-			settingsVerifier := RepoSettingsVerifier{repo: repo, githubClient: client, org: org}
+			verifyBranchProtection(client, repo, &policy.BranchProtection, reportedChecksHistory, fix, report)
+			codeOwnersVerifier := CodeOwnersVerifier{client: client, repoName: repo, policy: policy, report: report}
+			codeOwnersVerifier.VerifyCodeOwners(fix)
+			dependabotVerifier := DependabotConfigVerifier{client: client, repoName: repo, policy: &policy.Dependabot, report: report}
+			dependabotVerifier.VerifyDependabotConfig(fix)
+			requiredFilesVerifier := RequiredFilesVerifier{client: client, repoName: repo, files: policy.Files, report: report}
+			requiredFilesVerifier.VerifyRequiredFiles(fix)
+			permissionsVerifier := PermissionsVerifier{client: client, repoName: repo, policy: &policy.Permissions, report: report}
+			permissionsVerifier.VerifyPermissions(fix)
+			actionsSettingsVerifier := ActionsSettingsVerifier{client: client, repoName: repo, policy: &policy.Actions, report: report}
+			actionsSettingsVerifier.VerifyActionsSettings(fix)
+			UnifyLabels(repo, client, &policy.Labels, fix)
+			settingsVerifier := RepoSettingsVerifier{repo: repo, githubClient: client, org: org, policy: &policy.RepoSettings, report: report}
 			settingsVerifier.VerifyRepoSettings(fix)
 			webHookVerifier := WebHookVerifier{secrets: secrets, repo: repo, githubClient: client, org: org}
 			webHookVerifier.VerifyWebHooks(fix)
 		}
+		if report != nil {
+			report.writeToFile(reportFile)
+		}
 	},
 }
 
+func verifyBranchProtection(client *github.Client, repo string, policy *BranchProtectionPolicy, reportedChecksHistory int, fix bool, report *findingsReport) {
+	switch policy.Backend {
+	case classicBranchProtectionBackend:
+		branchProtectionVerifier := BranchProtectionVerifier{client: client, repoName: repo, policy: policy, reportedChecksHistory: reportedChecksHistory, report: report}
+		branchProtectionVerifier.CheckIfBranchProtectionIsApplied(fix)
+	case rulesetsBranchProtectionBackend:
+		rulesetVerifier := RulesetVerifier{client: client, repoName: repo, policy: policy, reportedChecksHistory: reportedChecksHistory, report: report}
+		rulesetVerifier.CheckIfRulesetsAreApplied(fix)
+	default:
+		panic(fmt.Sprintf("Unsupported branch protection backend '%v'. Supported backends are '%v' and '%v'.", policy.Backend, classicBranchProtectionBackend, rulesetsBranchProtectionBackend))
+	}
+}
+
+func readPolicyFromFlags(cmd *cobra.Command) *Policy {
+	policyFile, err := cmd.Flags().GetString("policy")
+	if err != nil {
+		panic(fmt.Sprintf("Could not read parameter policy: %v", err.Error()))
+	}
+	return ReadPolicyFromYaml(policyFile, cmd.Flags().Changed("policy"))
+}
+
 func getDefaultConfigFile() string {
 	homedir, err := os.UserHomeDir()
 	if err != nil {
@@ -48,5 +95,8 @@ func getDefaultConfigFile() string {
 func init() {
 	configureRepoCmd.Flags().Bool("fix", false, "If this flag is set, github-keeper fixed the findings. Otherwise it just prints the diff.")
 	configureRepoCmd.Flags().String("secrets", getDefaultConfigFile(), "Use a different secrets file location")
+	configureRepoCmd.Flags().String("policy", getDefaultPolicyFile(), "Use a different policy file location")
+	configureRepoCmd.Flags().Int("verify-reported-checks", 0, "Warn about required checks that were not reported for the given number of recent commits and the open pull requests. 0 disables the verification.")
+	configureRepoCmd.Flags().String("report", "", "Write the findings, e.g. the differences to the expected branch protections, as JSON to the given file")
 	rootCmd.AddCommand(configureRepoCmd)
 }
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"gopkg.in/yaml.v3"
+)
+
+const missingDependabotConfigFinding = "missing-dependabot-config"
+const invalidDependabotConfigFinding = "invalid-dependabot-config"
+const missingDependabotEcosystemFinding = "missing-dependabot-ecosystem"
+const dependabotScheduleFinding = "dependabot-schedule"
+
+// dependabotConfigLocations are the locations where Dependabot looks for its configuration.
+var dependabotConfigLocations = []string{".github/dependabot.yml", ".github/dependabot.yaml"}
+
+const dependabotBranch = "github-keeper/dependabot"
+
+// dependabotEcosystem is a package ecosystem of Dependabot and the files that show that a repository uses it.
+type dependabotEcosystem struct {
+	name          string
+	evidenceFiles []string
+}
+
+// githubActionsEcosystem is detected by the workflow files instead of evidence files.
+const githubActionsEcosystem = "github-actions"
+
+var dependabotEcosystems = []dependabotEcosystem{
+	{name: "gomod", evidenceFiles: []string{"go.mod"}},
+	{name: "maven", evidenceFiles: []string{"pom.xml"}},
+	{name: "npm", evidenceFiles: []string{"package.json"}},
+	{name: "pip", evidenceFiles: []string{"requirements.txt", "pyproject.toml"}},
+}
+
+// dependabotConfig is the content of dependabot.yml. Attributes that github-keeper does not manage are kept, so that
+// a proposed configuration does not lose them.
+type dependabotConfig struct {
+	Version int                    `yaml:"version"`
+	Updates []dependabotUpdate     `yaml:"updates"`
+	Other   map[string]interface{} `yaml:",inline"`
+}
+
+type dependabotUpdate struct {
+	PackageEcosystem string                 `yaml:"package-ecosystem"`
+	Directory        string                 `yaml:"directory"`
+	Schedule         dependabotSchedule     `yaml:"schedule"`
+	Other            map[string]interface{} `yaml:",inline"`
+}
+
+type dependabotSchedule struct {
+	Interval string                 `yaml:"interval"`
+	Other    map[string]interface{} `yaml:",inline"`
+}
+
+// DependabotConfigVerifier verifies that dependabot.yml enables version updates for all package ecosystems of a
+// repository. Dependabot alerts alone don't update dependencies.
+type DependabotConfigVerifier struct {
+	client   *github.Client
+	repoName string
+	policy   *DependabotPolicy
+	// report collects the findings. It may be nil.
+	report *findingsReport
+}
+
+func (verifier DependabotConfigVerifier) VerifyDependabotConfig(fix bool) {
+	if verifier.policy.Interval == "" {
+		return
+	}
+	repo, _, err := verifier.client.Repositories.Get(context.Background(), "exasol", verifier.repoName)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get repository exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+	}
+	source := githubContentSource{client: verifier.client, repoName: verifier.repoName, branch: repo.GetDefaultBranch()}
+	path, config, findings := verifier.verifyDependabotConfigOfSource(source)
+	for _, finding := range findings {
+		printFindingWarning(finding)
+	}
+	verifier.report.add(findings...)
+	if fix && len(findings) > 0 {
+		body := "github-keeper found the following problems:\n\n"
+		for _, finding := range findings {
+			body += "* " + finding.Message + "\n"
+		}
+		FileProposer{client: verifier.client, repoName: verifier.repoName}.Propose(fileProposal{files: []proposedFile{{path: path,
+			content: serializeDependabotConfig(config)}}, branch: dependabotBranch, title: "Update Dependabot configuration", body: body})
+	}
+}
+
+// verifyDependabotConfigOfSource compares the Dependabot configuration with the detected ecosystems. It returns the
+// path of the configuration, the configuration that fixes the findings and the findings.
+func (verifier DependabotConfigVerifier) verifyDependabotConfigOfSource(source repositoryContentSource) (string, *dependabotConfig, []Finding) {
+	ecosystems := detectDependabotEcosystems(source)
+	path, content, found := findDependabotConfigFile(source)
+	var findings []Finding
+	config := &dependabotConfig{Version: 2}
+	if !found {
+		findings = append(findings, Finding{Kind: missingDependabotConfigFinding, Repo: verifier.repoName, File: path,
+			Message: "the repository has no Dependabot configuration, so dependencies are never updated"})
+	} else if err := yaml.Unmarshal([]byte(content), config); err != nil {
+		config = &dependabotConfig{Version: 2}
+		findings = append(findings, Finding{Kind: invalidDependabotConfigFinding, Repo: verifier.repoName, File: path,
+			Message: fmt.Sprintf("the Dependabot configuration is invalid: %v", err.Error())})
+	}
+	for _, ecosystem := range ecosystems {
+		update := config.findUpdate(ecosystem, "/")
+		if update == nil {
+			findings = append(findings, Finding{Kind: missingDependabotEcosystemFinding, Repo: verifier.repoName, File: path,
+				Message: fmt.Sprintf("the repository uses %v, but Dependabot does not update it", ecosystem)})
+			config.Updates = append(config.Updates, dependabotUpdate{PackageEcosystem: ecosystem, Directory: "/",
+				Schedule: dependabotSchedule{Interval: verifier.policy.Interval}})
+		} else if update.Schedule.Interval != verifier.policy.Interval {
+			findings = append(findings, Finding{Kind: dependabotScheduleFinding, Repo: verifier.repoName, File: path,
+				Message: fmt.Sprintf("Dependabot updates %v with interval '%v' instead of '%v'", ecosystem, update.Schedule.Interval, verifier.policy.Interval)})
+			update.Schedule.Interval = verifier.policy.Interval
+		}
+	}
+	return path, config, findings
+}
+
+// detectDependabotEcosystems returns the ecosystems of the files in the root directory of the repository.
+func detectDependabotEcosystems(source repositoryContentSource) []string {
+	var result []string
+	for _, ecosystem := range dependabotEcosystems {
+		for _, file := range ecosystem.evidenceFiles {
+			if _, found := readOptionalContent(source, file); found {
+				result = append(result, ecosystem.name)
+				break
+			}
+		}
+	}
+	workflowFiles, err := source.listWorkflowFiles()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to list workflow files. Cause: %v", err.Error()))
+	}
+	if len(workflowFiles) > 0 {
+		result = append(result, githubActionsEcosystem)
+	}
+	return result
+}
+
+func findDependabotConfigFile(source repositoryContentSource) (string, string, bool) {
+	for _, location := range dependabotConfigLocations {
+		if content, found := readOptionalContent(source, location); found {
+			return location, content, true
+		}
+	}
+	return dependabotConfigLocations[0], "", false
+}
+
+func (config *dependabotConfig) findUpdate(ecosystem string, directory string) *dependabotUpdate {
+	for index := range config.Updates {
+		update := &config.Updates[index]
+		if update.PackageEcosystem == ecosystem && strings.Trim(update.Directory, "/") == strings.Trim(directory, "/") {
+			return update
+		}
+	}
+	return nil
+}
+
+func serializeDependabotConfig(config *dependabotConfig) string {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		panic(fmt.Sprintf("Failed to serialize Dependabot configuration. Cause: %v", err.Error()))
+	}
+	return buffer.String()
+}
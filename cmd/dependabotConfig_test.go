@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DependabotConfigSuite struct {
+	repositoryFixture
+}
+
+func TestDependabotConfigSuite(t *testing.T) {
+	suite.Run(t, new(DependabotConfigSuite))
+}
+
+func (suite *DependabotConfigSuite) SetupTest() {
+	suite.repositoryFixture.SetupTest()
+	suite.writeFile(".github/workflows/ci-build.yml", "on: [pull_request]\n")
+	suite.writeFile("go.mod", "module github.com/exasol/my-repo\n")
+}
+
+func (suite *DependabotConfigSuite) verify() (string, *dependabotConfig, []Finding) {
+	verifier := DependabotConfigVerifier{repoName: "my-repo", policy: &DependabotPolicy{Interval: "weekly"}}
+	return verifier.verifyDependabotConfigOfSource(suite.source())
+}
+
+func (suite *DependabotConfigSuite) TestDetectEcosystems() {
+	suite.writeFile("pom.xml", "<project/>")
+	suite.writeFile("pyproject.toml", "[tool.poetry]")
+	suite.Equal([]string{"gomod", "maven", "pip", "github-actions"}, detectDependabotEcosystems(suite.source()))
+}
+
+func (suite *DependabotConfigSuite) TestMissingConfig() {
+	path, config, findings := suite.verify()
+	suite.Equal(".github/dependabot.yml", path)
+	suite.Equal([]Finding{
+		{Kind: missingDependabotConfigFinding, Repo: "my-repo", File: ".github/dependabot.yml", Message: "the repository has no Dependabot configuration, so dependencies are never updated"},
+		{Kind: missingDependabotEcosystemFinding, Repo: "my-repo", File: ".github/dependabot.yml", Message: "the repository uses gomod, but Dependabot does not update it"},
+		{Kind: missingDependabotEcosystemFinding, Repo: "my-repo", File: ".github/dependabot.yml", Message: "the repository uses github-actions, but Dependabot does not update it"},
+	}, findings)
+	suite.Equal(`version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: weekly
+  - package-ecosystem: github-actions
+    directory: /
+    schedule:
+      interval: weekly
+`, serializeDependabotConfig(config))
+}
+
+func (suite *DependabotConfigSuite) TestCompliantConfig() {
+	suite.writeFile(".github/dependabot.yaml", `version: 2
+updates:
+  - package-ecosystem: "gomod"
+    directory: ""
+    schedule:
+      interval: "weekly"
+  - package-ecosystem: "github-actions"
+    directory: "/"
+    schedule:
+      interval: "weekly"
+`)
+	path, _, findings := suite.verify()
+	suite.Equal(".github/dependabot.yaml", path)
+	suite.Empty(findings)
+}
+
+func (suite *DependabotConfigSuite) TestWrongScheduleKeepsOtherAttributes() {
+	suite.writeFile(".github/dependabot.yml", `version: 2
+updates:
+  - package-ecosystem: "gomod"
+    directory: "/"
+    schedule:
+      interval: "daily"
+      time: "05:00"
+    labels: ["dependencies"]
+`)
+	_, config, findings := suite.verify()
+	suite.Equal([]Finding{
+		{Kind: dependabotScheduleFinding, Repo: "my-repo", File: ".github/dependabot.yml", Message: "Dependabot updates gomod with interval 'daily' instead of 'weekly'"},
+		{Kind: missingDependabotEcosystemFinding, Repo: "my-repo", File: ".github/dependabot.yml", Message: "the repository uses github-actions, but Dependabot does not update it"},
+	}, findings)
+	suite.Equal(`version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: weekly
+      time: "05:00"
+    labels:
+      - dependencies
+  - package-ecosystem: github-actions
+    directory: /
+    schedule:
+      interval: weekly
+`, serializeDependabotConfig(config))
+}
+
+func (suite *DependabotConfigSuite) TestInvalidConfig() {
+	suite.writeFile(".github/dependabot.yml", "version: [")
+	_, _, findings := suite.verify()
+	suite.Equal(invalidDependabotConfigFinding, findings[0].Kind)
+	suite.Len(findings, 3)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/spf13/cobra"
+)
+
+var explainProtectionCmd = &cobra.Command{
+	Use:   "explain-protection <repo-name> [more repo names]",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Show where each required status check of the branch protection comes from",
+	Long:  "Lists every status check that github-keeper requires for the protected branches of the given repositories together with its origin: the workflow file, job and matrix combination, SonarCloud or the existing protection.",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getGithubClient()
+		policy := readPolicyFromFlags(cmd)
+		for _, repo := range args {
+			ProtectionExplainer{repoName: repo, client: client, policy: &policy.BranchProtection}.Explain()
+		}
+	},
+}
+
+// ProtectionExplainer explains the required status checks of the protected branches of a repository.
+type ProtectionExplainer struct {
+	repoName string
+	client   *github.Client
+	policy   *BranchProtectionPolicy
+}
+
+// explainedCheck is a status check of the computed protection together with the reason why it is part of it.
+type explainedCheck struct {
+	context string
+	origin  string
+}
+
+func (explainer ProtectionExplainer) Explain() {
+	verifier := BranchProtectionVerifier{repoName: explainer.repoName, client: explainer.client, policy: explainer.policy}
+	repo := verifier.getRepo()
+	for _, branch := range verifier.getBranchesToProtect(repo) {
+		requiredChecks, complete := verifier.getRequiredChecksForBranch(branch)
+		configuredChecks := explainer.getConfiguredStatusChecks(branch.name)
+		protectionRequest := verifier.createProtectionRequest(branch, requiredChecks)
+		staleChecks := verifier.findStaleChecks(&github.Protection{RequiredStatusChecks: configuredChecks}, &protectionRequest, branch)
+		if !complete {
+			staleChecks = nil
+		}
+		existingChecks := getStatusCheckContexts(getExistingStatusChecks(configuredChecks))
+		checks := explainChecks(requiredChecks, existingChecks, staleChecks, explainer.policy.KeepChecks, branch.template.RequireStatusChecks, complete)
+		printExplainedChecks(fmt.Sprintf("exasol/%v %v", explainer.repoName, branch), checks)
+	}
+}
+
+// getConfiguredStatusChecks returns the required checks that are currently configured for the branch in the backend of
+// the policy or nil if the branch does not require checks.
+func (explainer ProtectionExplainer) getConfiguredStatusChecks(branch string) *github.RequiredStatusChecks {
+	if explainer.policy.Backend == rulesetsBranchProtectionBackend {
+		rules, _, err := explainer.client.Repositories.GetRulesForBranch(context.Background(), "exasol", explainer.repoName, branch)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to get rules for branch exasol/%v/%v. Cause: %v", explainer.repoName, branch, err.Error()))
+		}
+		rule := findRuleByType(rules, "required_status_checks")
+		if rule == nil {
+			return nil
+		}
+		return &github.RequiredStatusChecks{Contexts: getRuleCheckContexts(readRequiredStatusChecksRuleParameters(rule).RequiredStatusChecks)}
+	}
+	protection, resp, err := explainer.client.Repositories.GetBranchProtection(context.Background(), "exasol", explainer.repoName, branch)
+	if resp != nil && resp.StatusCode == 404 {
+		return nil
+	} else if err != nil {
+		panic(fmt.Sprintf("Failed to get branch protection of exasol/%v/%v. Cause: %v", explainer.repoName, branch, err.Error()))
+	}
+	return protection.GetRequiredStatusChecks()
+}
+
+// explainChecks combines the checks derived from the workflows with the checks of the existing protection in the same
+// way as configure-repo does. Only the stale checks are removed by configure-repo --fix, the other existing checks are
+// kept.
+func explainChecks(requiredChecks []requiredCheck, existingChecks []string, staleChecks []string, keepChecks []string, requireStatusChecks bool, complete bool) []explainedCheck {
+	var result []explainedCheck
+	for _, check := range requiredChecks {
+		result = append(result, explainedCheck{context: check.context, origin: check.describeOrigin()})
+	}
+	for _, existingCheck := range existingChecks {
+		if containsString(getCheckContexts(requiredChecks), existingCheck) {
+			continue
+		}
+		origin := "kept from existing protection"
+		if containsString(staleChecks, existingCheck) {
+			origin = "stale, will be removed by configure-repo --fix"
+		} else if !requireStatusChecks {
+			origin = "kept from existing protection, the protection template does not manage status checks"
+		} else if matchesAnyPattern(keepChecks, existingCheck) {
+			origin = "kept from existing protection, matches keepChecks of the policy"
+		} else if !complete {
+			origin = "kept from existing protection, some workflows could not be resolved"
+		}
+		result = append(result, explainedCheck{context: existingCheck, origin: origin})
+	}
+	return result
+}
+
+func printExplainedChecks(title string, checks []explainedCheck) {
+	fmt.Printf("Required checks of %v:\n", title)
+	if len(checks) == 0 {
+		fmt.Println("  none")
+		return
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, check := range checks {
+		fmt.Fprintf(writer, "  %v\t%v\n", check.context, check.origin)
+	}
+	err := writer.Flush()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to print required checks. Cause: %v", err.Error()))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(explainProtectionCmd)
+	explainProtectionCmd.Flags().String("policy", getDefaultPolicyFile(), "Use a different policy file location")
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExplainProtectionSuite struct {
+	suite.Suite
+}
+
+func TestExplainProtectionSuite(t *testing.T) {
+	suite.Run(t, new(ExplainProtectionSuite))
+}
+
+func (suite *ExplainProtectionSuite) TestExplainChecks() {
+	requiredChecks := []requiredCheck{
+		{context: "Build (linux)", origin: "workflow .github/workflows/ci.yml", job: "build", matrix: "os=linux"},
+		{context: "SonarCloud Code Analysis", origin: "SonarCloud"},
+	}
+	existingChecks := []string{"Build (linux)", "license/cla", "Old build"}
+	checks := explainChecks(requiredChecks, existingChecks, []string{"Old build"}, []string{"license/*"}, true, true)
+	suite.Equal([]explainedCheck{
+		{context: "Build (linux)", origin: "workflow .github/workflows/ci.yml, job build, matrix os=linux"},
+		{context: "SonarCloud Code Analysis", origin: "SonarCloud"},
+		{context: "license/cla", origin: "kept from existing protection, matches keepChecks of the policy"},
+		{context: "Old build", origin: "stale, will be removed by configure-repo --fix"},
+	}, checks)
+}
+
+func (suite *ExplainProtectionSuite) TestExplainChecksOfTemplateWithoutStatusChecks() {
+	checks := explainChecks(nil, []string{"manual-check"}, nil, nil, false, true)
+	suite.Equal([]explainedCheck{{context: "manual-check", origin: "kept from existing protection, the protection template does not manage status checks"}}, checks)
+}
+
+func (suite *ExplainProtectionSuite) TestExplainChecksWithIncompleteDerivation() {
+	checks := explainChecks(workflowChecks("build"), []string{"build", "manual (1)"}, nil, nil, true, false)
+	suite.Equal(explainedCheck{context: "manual (1)", origin: "kept from existing protection, some workflows could not be resolved"}, checks[1])
+}
+
+// TestExplainStaleChecksOfAppBoundProtection covers a protection that only binds its checks to apps (Checks without
+// Contexts) and whose workflows were all deleted.
+func (suite *ExplainProtectionSuite) TestExplainStaleChecksOfAppBoundProtection() {
+	policy := defaultBranchProtectionPolicy()
+	policy.KeepChecks = []string{"license/*"}
+	verifier := BranchProtectionVerifier{policy: &policy}
+	branch := protectedBranch{name: "main", isDefault: true, template: policy.findTemplateForBranch("main", true)}
+	configuredChecks := &github.RequiredStatusChecks{Checks: toRequiredStatusChecks(workflowChecks("old-job", "license/cla"))}
+	request := verifier.createProtectionRequest(branch, nil)
+	staleChecks := verifier.findStaleChecks(&github.Protection{RequiredStatusChecks: configuredChecks}, &request, branch)
+	checks := explainChecks(nil, getStatusCheckContexts(getExistingStatusChecks(configuredChecks)), staleChecks, policy.KeepChecks, true, true)
+	suite.Equal([]explainedCheck{
+		{context: "old-job", origin: "stale, will be removed by configure-repo --fix"},
+		{context: "license/cla", origin: "kept from existing protection, matches keepChecks of the policy"},
+	}, checks)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// FileProposer proposes new versions of files with a pull request instead of committing it to the default branch,
+// so that the maintainers of the repository can review and adapt it.
+type FileProposer struct {
+	client   *github.Client
+	repoName string
+}
+
+// proposedFile is a new version of a file in a repository.
+type proposedFile struct {
+	path    string
+	content string
+}
+
+// fileProposal contains the new versions of files for one pull request.
+type fileProposal struct {
+	files []proposedFile
+	// branch is the name of the branch of the pull request. It identifies the proposal, so that github-keeper does
+	// not propose the same change twice.
+	branch string
+	title  string
+	body   string
+}
+
+// Propose creates a branch with the new file versions and a pull request for it. If there is already an open pull
+// request for the branch, it does nothing. A branch without open pull request is reset and reused.
+func (proposer FileProposer) Propose(proposal fileProposal) {
+	if existing := proposer.findOpenPullRequest(proposal.branch); existing != nil {
+		fmt.Printf("exasol/%v already has an open pull request '%v': %v\n", proposer.repoName, proposal.title, existing.GetHTMLURL())
+		return
+	}
+	repo, _, err := proposer.client.Repositories.Get(context.Background(), "exasol", proposer.repoName)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get repository exasol/%v. Cause: %v", proposer.repoName, err.Error()))
+	}
+	defaultBranch := repo.GetDefaultBranch()
+	proposer.createBranch(defaultBranch, proposal.branch)
+	for _, file := range proposal.files {
+		proposer.commitFile(defaultBranch, proposal, file)
+	}
+	pullRequest, _, err := proposer.client.PullRequests.Create(context.Background(), "exasol", proposer.repoName, &github.NewPullRequest{
+		Title: &proposal.title,
+		Head:  &proposal.branch,
+		Base:  &defaultBranch,
+		Body:  &proposal.body,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create pull request '%v' in exasol/%v. Cause: %v", proposal.title, proposer.repoName, err.Error()))
+	}
+	fmt.Printf("Created pull request '%v' in exasol/%v: %v\n", proposal.title, proposer.repoName, pullRequest.GetHTMLURL())
+}
+
+func (proposer FileProposer) findOpenPullRequest(branch string) *github.PullRequest {
+	pullRequests, _, err := proposer.client.PullRequests.List(context.Background(), "exasol", proposer.repoName, &github.PullRequestListOptions{State: "open", Head: "exasol:" + branch})
+	if err != nil {
+		panic(fmt.Sprintf("Failed to list pull requests of exasol/%v. Cause: %v", proposer.repoName, err.Error()))
+	}
+	if len(pullRequests) == 0 {
+		return nil
+	}
+	return pullRequests[0]
+}
+
+// createBranch creates the branch of the proposal from the base branch. A branch that is left over from a closed pull
+// request of an earlier proposal is reset to the base branch.
+func (proposer FileProposer) createBranch(baseBranch string, branch string) {
+	baseRef, _, err := proposer.client.Git.GetRef(context.Background(), "exasol", proposer.repoName, "heads/"+baseBranch)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get branch %v of exasol/%v. Cause: %v", baseBranch, proposer.repoName, err.Error()))
+	}
+	ref := "refs/heads/" + branch
+	if proposer.branchExists(branch) {
+		fmt.Printf("Resetting branch %v of exasol/%v that is left over from a closed pull request to %v.\n", branch, proposer.repoName, baseBranch)
+		_, _, err = proposer.client.Git.UpdateRef(context.Background(), "exasol", proposer.repoName, &github.Reference{Ref: &ref, Object: baseRef.Object}, true)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to reset branch %v of exasol/%v. Cause: %v", branch, proposer.repoName, err.Error()))
+		}
+		return
+	}
+	_, _, err = proposer.client.Git.CreateRef(context.Background(), "exasol", proposer.repoName, &github.Reference{Ref: &ref, Object: baseRef.Object})
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create branch %v in exasol/%v. Cause: %v", branch, proposer.repoName, err.Error()))
+	}
+}
+
+func (proposer FileProposer) branchExists(branch string) bool {
+	_, response, err := proposer.client.Git.GetRef(context.Background(), "exasol", proposer.repoName, "heads/"+branch)
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			return false
+		}
+		panic(fmt.Sprintf("Failed to get branch %v of exasol/%v. Cause: %v", branch, proposer.repoName, err.Error()))
+	}
+	return true
+}
+
+// commitFile creates or updates the file on the branch of the proposal.
+func (proposer FileProposer) commitFile(baseBranch string, proposal fileProposal, file proposedFile) {
+	message := proposal.title
+	if len(proposal.files) > 1 {
+		message = fmt.Sprintf("%v: %v", proposal.title, file.path)
+	}
+	options := &github.RepositoryContentFileOptions{Message: &message, Content: []byte(file.content), Branch: &proposal.branch}
+	existingFile, _, response, err := proposer.client.Repositories.GetContents(context.Background(), "exasol", proposer.repoName, file.path, &github.RepositoryContentGetOptions{Ref: baseBranch})
+	if err != nil && (response == nil || response.StatusCode != 404) {
+		panic(fmt.Sprintf("Failed to get %v of exasol/%v. Cause: %v", file.path, proposer.repoName, err.Error()))
+	}
+	if existingFile != nil {
+		options.SHA = existingFile.SHA
+		_, _, err = proposer.client.Repositories.UpdateFile(context.Background(), "exasol", proposer.repoName, file.path, options)
+	} else {
+		_, _, err = proposer.client.Repositories.CreateFile(context.Background(), "exasol", proposer.repoName, file.path, options)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Failed to commit %v to branch %v of exasol/%v. Cause: %v", file.path, proposal.branch, proposer.repoName, err.Error()))
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+const unresolvableMatrixFinding = "unresolvable-matrix"
+const unresolvableReusableWorkflowFinding = "unresolvable-reusable-workflow"
+const unverifiableCheckFinding = "unverifiable-check"
+const conditionalTriggerFinding = "conditional-trigger"
+const skippedJobFinding = "skipped-job"
+const conditionalJobFinding = "conditional-job"
+
+const textReportFormat = "text"
+const jsonReportFormat = "json"
+
+// Finding is a problem that github-keeper detected but can't fix automatically.
+type Finding struct {
+	// Kind identifies the type of the finding, e.g. "unresolvable-matrix".
+	Kind string `json:"kind"`
+	// Repo is the name of the repository that contains the problem.
+	Repo string `json:"repo,omitempty"`
+	// File is the path or URL of the file that contains the problem.
+	File string `json:"file,omitempty"`
+	// Job is the name of the workflow job that contains the problem.
+	Job string `json:"job,omitempty"`
+	// Branch is the name of the branch that contains the problem.
+	Branch string `json:"branch,omitempty"`
+	// Ruleset is the name of the ruleset that contains the problem.
+	Ruleset string `json:"ruleset,omitempty"`
+	// Attribute, Expected and Actual describe a setting that differs from the policy.
+	Attribute string `json:"attribute,omitempty"`
+	Expected  string `json:"expected,omitempty"`
+	Actual    string `json:"actual,omitempty"`
+	Message   string `json:"message"`
+}
+
+func (finding Finding) String() string {
+	location := finding.File
+	if finding.Repo != "" {
+		location = fmt.Sprintf("exasol/%v %v", finding.Repo, location)
+	}
+	if finding.Job != "" {
+		location = fmt.Sprintf("%v (job '%v')", location, finding.Job)
+	}
+	return fmt.Sprintf("%v: %v", location, finding.Message)
+}
+
+func printFindingWarning(finding Finding) {
+	fmt.Printf("%vWarning: %v%v\n", consoleColorYellow, finding.String(), consoleColorReset)
+}
+
+// printFindings prints the findings as warnings (format "text") or as a JSON list (format "json").
+func printFindings(findings []Finding, format string) {
+	switch format {
+	case textReportFormat:
+		for _, finding := range findings {
+			printFindingWarning(finding)
+		}
+	case jsonReportFormat:
+		fmt.Println(string(serializeFindings(findings)))
+	default:
+		panic(fmt.Sprintf("Unsupported report format '%v'. Supported formats are '%v' and '%v'.", format, textReportFormat, jsonReportFormat))
+	}
+}
+
+func serializeFindings(findings []Finding) []byte {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	report, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to serialize findings. Cause: %v", err.Error()))
+	}
+	return report
+}
+
+// findingsReport collects findings of several repositories for a machine-readable report. A nil report ignores the
+// findings.
+type findingsReport struct {
+	findings []Finding
+}
+
+func (report *findingsReport) add(findings ...Finding) {
+	if report != nil {
+		report.findings = append(report.findings, findings...)
+	}
+}
+
+// writeToFile writes the findings as JSON list to the given file.
+func (report *findingsReport) writeToFile(fileName string) {
+	err := os.WriteFile(fileName, serializeFindings(report.findings), 0o644)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to write report %v. Cause: %v", fileName, err.Error()))
+	}
+}
+
+func readReportFormatFromFlags(cmd *cobra.Command) string {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		panic(fmt.Sprintf("Could not read parameter format: %v", err.Error()))
+	}
+	if format != textReportFormat && format != jsonReportFormat {
+		panic(fmt.Sprintf("Unsupported report format '%v'. Supported formats are '%v' and '%v'.", format, textReportFormat, jsonReportFormat))
+	}
+	return format
+}
@@ -7,7 +7,7 @@ import (
 	"path"
 
 	"github.com/alyu/configparser"
-	"github.com/google/go-github/v43/github"
+	"github.com/google/go-github/v57/github"
 	"golang.org/x/oauth2"
 )
 
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type jobConditionClass int
+
+const (
+	// jobAlwaysRuns means that the job has no condition.
+	jobAlwaysRuns jobConditionClass = iota
+	// jobConditional means that the job has a condition that might skip it for some pull requests.
+	jobConditional
+	// jobSkippedOnPullRequests means that the condition of the job excludes pull request events.
+	jobSkippedOnPullRequests
+)
+
+var eventNameEqualsPattern = regexp.MustCompile(`^github\.event_name==(?:'([^']*)'|"([^"]*)")$`)
+var eventNameNotEqualsPattern = regexp.MustCompile(`^github\.event_name!=(?:'([^']*)'|"([^"]*)")$`)
+var refEqualsPattern = regexp.MustCompile(`^github\.ref==(?:'([^']*)'|"([^"]*)")$`)
+var refStartsWithPattern = regexp.MustCompile(`^startswith\(github\.ref,(?:'([^']*)'|"([^"]*)")\)$`)
+var refTypeTagPattern = regexp.MustCompile(`^github\.ref_type==(?:'tag'|"tag")$`)
+var statusFunctionPattern = regexp.MustCompile(`(always|failure|cancelled)\(\)`)
+
+// classifyJobCondition classifies the `if:` condition of a job. reportingEvents are the events whose runs report the
+// checks of the workflow for pull requests, e.g. "pull_request" or "push" for workflows that run on pushes to any
+// branch. Without reporting events the condition is classified for "pull_request". Conditions that consist of
+// conjunctions are skipped on pull requests if one of the operands is false for all of these runs, e.g.
+// `github.event_name == 'push'` for a workflow that only reports checks from pull_request runs.
+func classifyJobCondition(condition string, reportingEvents []string) (jobConditionClass, string) {
+	if len(reportingEvents) == 0 {
+		reportingEvents = []string{pullRequestEvent}
+	}
+	normalized := normalizeCondition(condition)
+	if normalized == "" || normalized == "true" {
+		return jobAlwaysRuns, ""
+	} else if normalized == "false" {
+		return jobSkippedOnPullRequests, "the job is disabled"
+	} else if strings.Contains(normalized, "||") {
+		return jobConditional, ""
+	}
+	for _, operand := range strings.Split(normalized, "&&") {
+		if excludesPullRequests(trimEnclosingParentheses(operand), reportingEvents) {
+			return jobSkippedOnPullRequests, fmt.Sprintf("its condition '%v' excludes pull requests", strings.TrimSpace(condition))
+		}
+	}
+	return jobConditional, ""
+}
+
+func normalizeCondition(condition string) string {
+	normalized := strings.TrimSpace(condition)
+	if strings.HasPrefix(normalized, "${{") && strings.HasSuffix(normalized, "}}") {
+		normalized = strings.TrimSuffix(strings.TrimPrefix(normalized, "${{"), "}}")
+	}
+	return strings.ToLower(strings.Join(strings.Fields(normalized), ""))
+}
+
+func trimEnclosingParentheses(operand string) string {
+	for strings.HasPrefix(operand, "(") && strings.HasSuffix(operand, ")") {
+		operand = operand[1 : len(operand)-1]
+	}
+	return operand
+}
+
+// excludesPullRequests checks if the operand is false for the runs of all reporting events. Pull request runs have the
+// ref refs/pull/<number>/merge, push runs the ref refs/heads/<head branch> of the pull request.
+func excludesPullRequests(operand string, reportingEvents []string) bool {
+	for _, event := range reportingEvents {
+		if !excludesRunOfEvent(operand, event) {
+			return false
+		}
+	}
+	return true
+}
+
+func excludesRunOfEvent(operand string, event string) bool {
+	refPrefix := "refs/pull/"
+	if event == pushEvent {
+		refPrefix = "refs/heads/"
+	}
+	if match := eventNameEqualsPattern.FindStringSubmatch(operand); match != nil {
+		return match[1]+match[2] != event
+	} else if match := eventNameNotEqualsPattern.FindStringSubmatch(operand); match != nil {
+		return match[1]+match[2] == event
+	} else if refTypeTagPattern.MatchString(operand) {
+		return true
+	} else if match := refEqualsPattern.FindStringSubmatch(operand); match != nil {
+		// the number of the pull request and the head branch are not known in advance
+		return event == pushEvent || !strings.HasPrefix(match[1]+match[2], refPrefix)
+	} else if match := refStartsWithPattern.FindStringSubmatch(operand); match != nil {
+		prefix := match[1] + match[2]
+		return !strings.HasPrefix(refPrefix, prefix) && (event == pushEvent || !strings.HasPrefix(prefix, refPrefix))
+	}
+	return false
+}
+
+// getJobsSkippedOnPullRequests returns the reasons by job key for all jobs that don't run for pull requests. This
+// includes jobs that need a skipped job unless they use a status function like `always()`.
+func getJobsSkippedOnPullRequests(jobs map[string]JobDescriptionInt, reportingEvents []string) map[string]string {
+	skippedJobs := map[string]string{}
+	for jobKey, job := range jobs {
+		if class, reason := classifyJobCondition(job.getCondition(), reportingEvents); class == jobSkippedOnPullRequests {
+			skippedJobs[jobKey] = reason
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for jobKey, job := range jobs {
+			if _, skipped := skippedJobs[jobKey]; skipped || statusFunctionPattern.MatchString(job.getCondition()) {
+				continue
+			}
+			for _, neededJob := range job.getNeeds() {
+				if _, skipped := skippedJobs[neededJob]; skipped {
+					skippedJobs[jobKey] = fmt.Sprintf("it needs the job '%v' that is skipped for pull requests", neededJob)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return skippedJobs
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type JobConditionsSuite struct {
+	suite.Suite
+}
+
+func TestJobConditionsSuite(t *testing.T) {
+	suite.Run(t, new(JobConditionsSuite))
+}
+
+func (suite *JobConditionsSuite) TestClassifyJobCondition() {
+	cases := map[string]jobConditionClass{
+		"":                            jobAlwaysRuns,
+		"${{ true }}":                 jobAlwaysRuns,
+		"false":                       jobSkippedOnPullRequests,
+		"github.event_name == 'push'": jobSkippedOnPullRequests,
+		"${{ github.event_name != 'pull_request' }}":                           jobSkippedOnPullRequests,
+		"github.event_name == 'pull_request'":                                  jobConditional,
+		"github.ref == 'refs/heads/main'":                                      jobSkippedOnPullRequests,
+		"startsWith(github.ref, 'refs/tags/')":                                 jobSkippedOnPullRequests,
+		"startsWith(github.ref, 'refs/')":                                      jobConditional,
+		"github.actor != 'dependabot[bot]'":                                    jobConditional,
+		"github.actor != 'dependabot[bot]' && github.event_name == 'schedule'": jobSkippedOnPullRequests,
+		"github.event_name == 'push' || github.event_name == 'pull_request'":   jobConditional,
+	}
+	for condition, expectedClass := range cases {
+		class, _ := classifyJobCondition(condition, []string{pullRequestEvent})
+		suite.Equal(expectedClass, class, condition)
+	}
+}
+
+func (suite *JobConditionsSuite) TestClassifyJobConditionForPushRuns() {
+	cases := map[string]jobConditionClass{
+		"github.event_name == 'push'":               jobConditional,
+		"github.event_name == 'pull_request'":       jobSkippedOnPullRequests,
+		"github.event_name != 'push'":               jobSkippedOnPullRequests,
+		"github.event_name != 'pull_request'":       jobConditional,
+		"github.ref == 'refs/heads/main'":           jobSkippedOnPullRequests,
+		"startsWith(github.ref, 'refs/heads/')":     jobConditional,
+		"startsWith(github.ref, 'refs/heads/main')": jobSkippedOnPullRequests,
+		"github.ref_type == 'tag'":                  jobSkippedOnPullRequests,
+	}
+	for condition, expectedClass := range cases {
+		class, _ := classifyJobCondition(condition, []string{pushEvent})
+		suite.Equal(expectedClass, class, condition)
+	}
+}
+
+func (suite *JobConditionsSuite) TestEventNameGuardInPushTriggeredWorkflow() {
+	definition, err := WorkflowDefinitionParser{}.ParseWorkflowDefinition(`
+on:
+  push:
+jobs:
+  build:
+    if: github.event_name == 'push'
+    runs-on: ubuntu-latest
+`)
+	suite.NoError(err)
+	jobNames, findings, err := definition.GetJobNamesAndFindings()
+	suite.NoError(err)
+	suite.Equal([]string{"build"}, jobNames)
+	suite.Equal([]Finding{{Kind: conditionalJobFinding, Job: "build", Message: "The job has the condition 'github.event_name == 'push'' and might be skipped for some pull requests. Please verify that its checks are reported."}}, findings)
+}
+
+func (suite *JobConditionsSuite) TestSkippedJobsWithNeeds() {
+	definition, err := WorkflowDefinitionParser{}.ParseWorkflowDefinition(`
+on:
+  pull_request:
+  push:
+    branches: [main]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+  release:
+    if: github.event_name == 'push'
+    needs: build
+    runs-on: ubuntu-latest
+  publish:
+    needs: [build, release]
+    runs-on: ubuntu-latest
+  report:
+    if: always()
+    needs: release
+    runs-on: ubuntu-latest
+  dependabot:
+    if: github.actor != 'dependabot[bot]'
+    runs-on: ubuntu-latest
+`)
+	suite.NoError(err)
+	jobNames, findings, err := definition.GetJobNamesAndFindings()
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"build", "report", "dependabot"}, jobNames)
+	findingsByJob := map[string]Finding{}
+	for _, finding := range findings {
+		findingsByJob[finding.Job] = finding
+	}
+	suite.Equal("The checks of this job are not required because its condition 'github.event_name == 'push'' excludes pull requests.", findingsByJob["release"].Message)
+	suite.Equal("The checks of this job are not required because it needs the job 'release' that is skipped for pull requests.", findingsByJob["publish"].Message)
+	suite.Equal(conditionalJobFinding, findingsByJob["dependabot"].Kind)
+	suite.Equal(conditionalJobFinding, findingsByJob["report"].Kind)
+}
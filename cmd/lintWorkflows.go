@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var lintWorkflowsCmd = &cobra.Command{
+	Use:   "lint-workflows <repo-name> [more repo names]",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Report hygiene issues in the GitHub workflows of the given repositories",
+	Long:  "Reports missing permissions, actions that are not pinned to a SHA or version tag, jobs without timeout, deprecated workflow commands, obsolete runner images and job names that collide as status check contexts.",
+	Run: func(cmd *cobra.Command, args []string) {
+		format := readReportFormatFromFlags(cmd)
+		client := getGithubClient()
+		var findings []Finding
+		for _, repoName := range args {
+			repo, _, err := client.Repositories.Get(context.Background(), "exasol", repoName)
+			if err != nil {
+				panic(fmt.Sprintf("Failed to get repository exasol/%v. Cause: %v", repoName, err.Error()))
+			}
+			source := githubContentSource{client: client, repoName: repoName, branch: repo.GetDefaultBranch()}
+			repoFindings, err := WorkflowLinter{repoName: repoName, source: source}.Lint()
+			if err != nil {
+				panic(fmt.Sprintf("Failed to lint workflows of exasol/%v. Cause: %v", repoName, err.Error()))
+			}
+			findings = append(findings, repoFindings...)
+		}
+		printFindings(findings, format)
+	},
+}
+
+const invalidWorkflowFinding = "invalid-workflow"
+const missingPermissionsFinding = "missing-permissions"
+const unpinnedActionFinding = "unpinned-action"
+const missingTimeoutFinding = "missing-timeout"
+const deprecatedCommandFinding = "deprecated-command"
+const obsoleteRunnerFinding = "obsolete-runner"
+const duplicateJobNameFinding = "duplicate-job-name"
+
+var pinnedActionRefPattern = regexp.MustCompile(`^([0-9a-f]{40}|v\d+(\.\d+){0,2})$`)
+var deprecatedCommandPattern = regexp.MustCompile(`::(set-output|save-state|set-env|add-path)\b`)
+
+// obsoleteRunners are runner images that GitHub deprecated or removed.
+var obsoleteRunners = []string{"ubuntu-16.04", "ubuntu-18.04", "ubuntu-20.04", "macos-10.15", "macos-11", "macos-12", "windows-2016", "windows-2019"}
+
+// WorkflowLinter reports hygiene issues in the workflows of a repository.
+type WorkflowLinter struct {
+	repoName string
+	source   repositoryContentSource
+}
+
+func (linter WorkflowLinter) Lint() ([]Finding, error) {
+	workflowFiles, err := linter.source.listWorkflowFiles()
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	checkOrigins := map[string][]string{}
+	for _, workflowFile := range workflowFiles {
+		content, err := linter.source.readFile(workflowFile)
+		if err != nil {
+			return nil, err
+		}
+		workflow, err := WorkflowDefinitionParser{loader: linter.source}.ParseWorkflowDefinition(content)
+		if err != nil {
+			findings = append(findings, Finding{Kind: invalidWorkflowFinding, Message: fmt.Sprintf("The workflow can't be parsed: %v.", err.Error())})
+		} else {
+			findings = append(findings, lintWorkflow(workflow.rawDefinition)...)
+			addCheckOrigins(checkOrigins, workflow, workflowFile)
+		}
+		for index := range findings {
+			if findings[index].File == "" {
+				findings[index].File = workflowFile
+			}
+		}
+	}
+	findings = append(findings, findDuplicateCheckNames(checkOrigins)...)
+	for index := range findings {
+		findings[index].Repo = linter.repoName
+	}
+	return findings, nil
+}
+
+func lintWorkflow(workflow *workflowDefinitionInt) []Finding {
+	var findings []Finding
+	jobKeys := getSortedJobKeys(workflow.Jobs)
+	if workflow.Permissions == nil && !allJobsDefinePermissions(workflow.Jobs) {
+		findings = append(findings, Finding{Kind: missingPermissionsFinding, Message: "The workflow does not define top-level permissions. Please restrict the permissions of the GITHUB_TOKEN."})
+	}
+	for _, jobKey := range jobKeys {
+		job := workflow.Jobs[jobKey]
+		findings = append(findings, lintJob(jobKey, &job)...)
+	}
+	return findings
+}
+
+func lintJob(jobKey string, job *JobDescriptionInt) []Finding {
+	var findings []Finding
+	addFinding := func(kind string, message string) {
+		findings = append(findings, Finding{Kind: kind, Job: jobKey, Message: message})
+	}
+	if job.Uses != nil {
+		if !isActionPinned(*job.Uses) {
+			addFinding(unpinnedActionFinding, fmt.Sprintf("The reusable workflow '%v' is not pinned to a SHA or version tag.", *job.Uses))
+		}
+		return findings
+	}
+	if job.TimeoutMinutes == nil {
+		addFinding(missingTimeoutFinding, "The job does not define timeout-minutes. Hanging jobs run for 6 hours.")
+	}
+	for _, runner := range readStringList(job.RunsOn) {
+		if containsString(obsoleteRunners, runner) {
+			addFinding(obsoleteRunnerFinding, fmt.Sprintf("The job uses the obsolete runner image '%v'.", runner))
+		}
+	}
+	for _, step := range job.Steps {
+		if step.Uses != nil && !isActionPinned(*step.Uses) {
+			addFinding(unpinnedActionFinding, fmt.Sprintf("The action '%v' is not pinned to a SHA or version tag.", *step.Uses))
+		}
+		if step.Run != nil {
+			for _, match := range deprecatedCommandPattern.FindAllStringSubmatch(*step.Run, -1) {
+				addFinding(deprecatedCommandFinding, fmt.Sprintf("The job uses the deprecated workflow command '%v'. Please use environment files instead.", match[1]))
+			}
+		}
+	}
+	return findings
+}
+
+// isActionPinned checks if an action or reusable workflow reference uses a commit SHA or a version tag. Local actions
+// and docker images are not checked.
+func isActionPinned(uses string) bool {
+	if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") {
+		return true
+	}
+	separator := strings.LastIndex(uses, "@")
+	if separator < 0 {
+		return false
+	}
+	return pinnedActionRefPattern.MatchString(uses[separator+1:])
+}
+
+func allJobsDefinePermissions(jobs map[string]JobDescriptionInt) bool {
+	for _, job := range jobs {
+		if job.Permissions == nil {
+			return false
+		}
+	}
+	return len(jobs) > 0
+}
+
+func getSortedJobKeys(jobs map[string]JobDescriptionInt) []string {
+	keys := make([]string, 0, len(jobs))
+	for key := range jobs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// addCheckOrigins adds the check names of all jobs of the workflow, independent of their triggers and conditions.
+// Workflows that are only called by other workflows are skipped since their checks are named after the calling job.
+func addCheckOrigins(checkOrigins map[string][]string, workflow *workflowDefinition, workflowFile string) {
+	if _, isReusable := workflow.Trigger.Events[workflowCallEvent]; isReusable && len(workflow.Trigger.Events) == 1 {
+		return
+	}
+	for _, jobKey := range getSortedJobKeys(workflow.rawDefinition.Jobs) {
+		job := workflow.rawDefinition.Jobs[jobKey]
+		checks, _, err := workflow.getChecksForJob(jobKey, &job)
+		if err != nil {
+			continue
+		}
+		for _, check := range checks {
+			checkOrigins[check.name] = append(checkOrigins[check.name], fmt.Sprintf("%v (job '%v')", workflowFile, jobKey))
+		}
+	}
+}
+
+func findDuplicateCheckNames(checkOrigins map[string][]string) []Finding {
+	var findings []Finding
+	checkNames := make([]string, 0, len(checkOrigins))
+	for checkName := range checkOrigins {
+		checkNames = append(checkNames, checkName)
+	}
+	sort.Strings(checkNames)
+	for _, checkName := range checkNames {
+		if origins := checkOrigins[checkName]; len(origins) > 1 {
+			findings = append(findings, Finding{Kind: duplicateJobNameFinding, File: workflowsDirectory, Message: fmt.Sprintf("The status check '%v' is produced by multiple jobs: %v.", checkName, strings.Join(origins, ", "))})
+		}
+	}
+	return findings
+}
+
+func init() {
+	rootCmd.AddCommand(lintWorkflowsCmd)
+	lintWorkflowsCmd.Flags().String("format", textReportFormat, "Output format: text or json")
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LintWorkflowsSuite struct {
+	suite.Suite
+}
+
+func TestLintWorkflowsSuite(t *testing.T) {
+	suite.Run(t, new(LintWorkflowsSuite))
+}
+
+func (suite *LintWorkflowsSuite) lint(content string) []Finding {
+	definition, err := WorkflowDefinitionParser{}.ParseWorkflowDefinition(content)
+	suite.NoError(err)
+	return lintWorkflow(definition.rawDefinition)
+}
+
+func (suite *LintWorkflowsSuite) TestCleanWorkflow() {
+	findings := suite.lint(`
+on: [pull_request]
+permissions:
+  contents: read
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    timeout-minutes: 10
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@0c52d547c9bc32b1aa3301fd7a9cb496313a4491
+      - uses: ./.github/actions/local
+      - run: echo "name=value" >> $GITHUB_OUTPUT
+`)
+	suite.Empty(findings)
+}
+
+func (suite *LintWorkflowsSuite) TestMissingPermissions() {
+	findings := suite.lint(`
+on: [pull_request]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    timeout-minutes: 10
+`)
+	suite.Equal([]Finding{{Kind: missingPermissionsFinding, Message: "The workflow does not define top-level permissions. Please restrict the permissions of the GITHUB_TOKEN."}}, findings)
+}
+
+func (suite *LintWorkflowsSuite) TestPermissionsOfAllJobs() {
+	findings := suite.lint(`
+on: [pull_request]
+jobs:
+  build:
+    permissions: read-all
+    runs-on: ubuntu-latest
+    timeout-minutes: 10
+`)
+	suite.Empty(findings)
+}
+
+func (suite *LintWorkflowsSuite) TestJobFindings() {
+	findings := suite.lint(`
+on: [pull_request]
+permissions: read-all
+jobs:
+  build:
+    runs-on: [ubuntu-18.04]
+    steps:
+      - uses: actions/checkout@main
+      - uses: actions/cache
+      - run: |
+          echo "::set-output name=version::1.0"
+          echo "::add-path::/opt/bin"
+  call:
+    uses: exasol/shared/.github/workflows/build.yml@main
+`)
+	suite.Equal([]Finding{
+		{Kind: missingTimeoutFinding, Job: "build", Message: "The job does not define timeout-minutes. Hanging jobs run for 6 hours."},
+		{Kind: obsoleteRunnerFinding, Job: "build", Message: "The job uses the obsolete runner image 'ubuntu-18.04'."},
+		{Kind: unpinnedActionFinding, Job: "build", Message: "The action 'actions/checkout@main' is not pinned to a SHA or version tag."},
+		{Kind: unpinnedActionFinding, Job: "build", Message: "The action 'actions/cache' is not pinned to a SHA or version tag."},
+		{Kind: deprecatedCommandFinding, Job: "build", Message: "The job uses the deprecated workflow command 'set-output'. Please use environment files instead."},
+		{Kind: deprecatedCommandFinding, Job: "build", Message: "The job uses the deprecated workflow command 'add-path'. Please use environment files instead."},
+		{Kind: unpinnedActionFinding, Job: "call", Message: "The reusable workflow 'exasol/shared/.github/workflows/build.yml@main' is not pinned to a SHA or version tag."},
+	}, findings)
+}
+
+func (suite *LintWorkflowsSuite) TestDuplicateCheckNamesAcrossWorkflows() {
+	repositoryRoot := suite.T().TempDir()
+	workflowsDir := filepath.Join(repositoryRoot, ".github", "workflows")
+	suite.NoError(os.MkdirAll(workflowsDir, 0o755))
+	workflow := `
+on: [pull_request]
+permissions: read-all
+jobs:
+  build:
+    name: Build
+    runs-on: ubuntu-latest
+    timeout-minutes: 10
+`
+	suite.NoError(os.WriteFile(filepath.Join(workflowsDir, "a.yml"), []byte(workflow), 0o600))
+	suite.NoError(os.WriteFile(filepath.Join(workflowsDir, "b.yml"), []byte(workflow), 0o600))
+	suite.NoError(os.WriteFile(filepath.Join(workflowsDir, "broken.yml"), []byte("on: [pull_request"), 0o600))
+	source, err := newLocalContentSource(repositoryRoot, nil)
+	suite.NoError(err)
+	findings, err := WorkflowLinter{repoName: "my-repo", source: source}.Lint()
+	suite.NoError(err)
+	suite.Len(findings, 2)
+	suite.Equal(Finding{Kind: invalidWorkflowFinding, Repo: "my-repo", File: ".github/workflows/broken.yml", Message: findings[0].Message}, findings[0])
+	suite.Equal(Finding{Kind: duplicateJobNameFinding, Repo: "my-repo", File: ".github/workflows", Message: "The status check 'Build' is produced by multiple jobs: .github/workflows/a.yml (job 'build'), .github/workflows/b.yml (job 'build')."}, findings[1])
+}
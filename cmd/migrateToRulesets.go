@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/spf13/cobra"
+)
+
+var migrateToRulesetsCmd = &cobra.Command{
+	Use:   "migrate-to-rulesets <repo-name> [more repo names]",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Convert the classic branch protections of the given repositories into equivalent rulesets",
+	Long:  "Converts the classic branch protection of each protected branch into an equivalent repository ruleset. In fix mode the ruleset is created and the classic branch protection is removed afterwards. Branch protections with settings that rulesets can't express are only migrated with --drop-unsupported-settings.",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getGithubClient()
+		fix, err := cmd.Flags().GetBool("fix")
+		if err != nil {
+			panic(fmt.Sprintf("Could not read parameter fix: %v", err.Error()))
+		}
+		dropUnsupportedSettings, err := cmd.Flags().GetBool("drop-unsupported-settings")
+		if err != nil {
+			panic(fmt.Sprintf("Could not read parameter drop-unsupported-settings: %v", err.Error()))
+		}
+		policy := readPolicyFromFlags(cmd)
+		for _, repo := range args {
+			migrator := RulesetMigrator{client: client, repoName: repo, policy: &policy.BranchProtection, dropUnsupportedSettings: dropUnsupportedSettings}
+			migrator.MigrateClassicProtection(fix)
+		}
+	},
+}
+
+// inconsistentProtectionFinding is reported if branches that share a ruleset have different classic branch protections.
+const inconsistentProtectionFinding = "inconsistent-protection"
+
+// RulesetMigrator converts classic branch protections into repository rulesets.
+type RulesetMigrator struct {
+	repoName string
+	client   *github.Client
+	policy   *BranchProtectionPolicy
+	// dropUnsupportedSettings allows migrating branch protections whose settings rulesets can't express. These settings
+	// are lost when the classic branch protection is removed.
+	dropUnsupportedSettings bool
+}
+
+// migrationGroup contains the protected branches that are migrated to the same ruleset.
+type migrationGroup struct {
+	rulesetName string
+	refs        []string
+	branches    []string
+}
+
+func (migrator RulesetMigrator) MigrateClassicProtection(fix bool) {
+	rulesetVerifier := RulesetVerifier{repoName: migrator.repoName, client: migrator.client, policy: migrator.policy}
+	existingRulesets := rulesetVerifier.listRulesets()
+	repo := rulesetVerifier.getBranchProtectionVerifier().getRepo()
+	for _, group := range groupBranchesForMigration(migrator.policy, migrator.listProtectedBranches(), repo.GetDefaultBranch()) {
+		protections := migrator.getClassicProtections(group.branches)
+		if len(protections) == 0 {
+			continue
+		}
+		if findRulesetByName(existingRulesets, group.rulesetName) != nil {
+			migrator.finishMigration(group, protections, fix)
+			continue
+		}
+		ruleset := migrator.convertGroup(group, protections)
+		if ruleset == nil {
+			continue
+		}
+		if fix {
+			migrator.replaceProtectionsByRuleset(group, protections, ruleset)
+		} else {
+			fmt.Printf("exasol/%v uses classic branch protections for %v. Use --fix to migrate them to ruleset '%v'.\n", migrator.repoName, formatList(sortedKeysOfProtections(protections)), group.rulesetName)
+		}
+	}
+}
+
+// groupBranchesForMigration assigns the protected branches to the rulesets that configure-repo manages with the
+// rulesets backend, so that it adopts the migrated rulesets. Branches that no pattern of the policy matches are
+// migrated to a ruleset of their own named <rulesetName>-migrated-<branch>.
+func groupBranchesForMigration(policy *BranchProtectionPolicy, branches []string, defaultBranch string) []*migrationGroup {
+	rulesetVerifier := RulesetVerifier{policy: policy}
+	var groups []*migrationGroup
+	groupsByName := make(map[string]*migrationGroup)
+	for _, branch := range branches {
+		isDefault := branch == defaultBranch
+		var group migrationGroup
+		if rule := policy.findRuleForBranch(branch, isDefault); rule != nil {
+			group = migrationGroup{rulesetName: rulesetVerifier.getRulesetName(rule.Template), refs: rulesetVerifier.getRefConditions(rule.Template)}
+		} else {
+			ref := "refs/heads/" + branch
+			if isDefault {
+				ref = defaultBranchRulesetRef
+			}
+			group = migrationGroup{rulesetName: fmt.Sprintf("%v-migrated-%v", policy.RulesetName, branch), refs: []string{ref}}
+		}
+		existing, found := groupsByName[group.rulesetName]
+		if !found {
+			existing = &group
+			groupsByName[group.rulesetName] = existing
+			groups = append(groups, existing)
+		}
+		existing.branches = append(existing.branches, branch)
+	}
+	return groups
+}
+
+// getClassicProtections returns the classic branch protections of the branches. Branches that are only protected by
+// rulesets are not contained.
+func (migrator RulesetMigrator) getClassicProtections(branches []string) map[string]*github.Protection {
+	result := make(map[string]*github.Protection)
+	for _, branch := range branches {
+		protection, response, err := migrator.client.Repositories.GetBranchProtection(context.Background(), "exasol", migrator.repoName, branch)
+		if err != nil {
+			if response != nil && response.StatusCode == 404 {
+				continue
+			}
+			panic(fmt.Sprintf("Failed to get branch protection of exasol/%v/%v. Cause: %v", migrator.repoName, branch, err.Error()))
+		}
+		result[branch] = protection
+	}
+	return result
+}
+
+// convertGroup converts the classic branch protections of the group into one ruleset. It returns nil if the
+// protections use unsupported settings or differ from each other, since one ruleset can't express them.
+func (migrator RulesetMigrator) convertGroup(group *migrationGroup, protections map[string]*github.Protection) *github.Ruleset {
+	var result *github.Ruleset
+	var firstBranch string
+	for _, branch := range sortedKeysOfProtections(protections) {
+		protection := protections[branch]
+		unsupportedSettings := findUnsupportedSettings(protection)
+		if len(unsupportedSettings) > 0 {
+			printFindingWarning(Finding{Kind: unsupportedProtectionSettingFinding, Repo: migrator.repoName, Branch: branch,
+				Message: fmt.Sprintf("The branch protection of branch %v uses %v that rulesets don't support.", branch, strings.Join(unsupportedSettings, ", "))})
+			if !migrator.dropUnsupportedSettings {
+				fmt.Printf("Skipping migration of exasol/%v/%v. Use --drop-unsupported-settings to migrate it without these settings.\n", migrator.repoName, branch)
+				return nil
+			}
+		}
+		ruleset := convertProtectionToRuleset(group.rulesetName, group.refs, protection)
+		if result == nil {
+			result = ruleset
+			firstBranch = branch
+		} else if differences := diffRuleset(result, ruleset, nil); len(differences) > 0 {
+			printFindingWarning(Finding{Kind: inconsistentProtectionFinding, Repo: migrator.repoName, Branch: branch, Ruleset: group.rulesetName,
+				Message: fmt.Sprintf("The branch protection of branch %v differs from the one of branch %v (%v), but both are migrated to ruleset '%v'. Skipping the migration.",
+					branch, firstBranch, differences[0], group.rulesetName)})
+			return nil
+		}
+	}
+	return result
+}
+
+// finishMigration removes the classic branch protections that are left over although the ruleset of the group already
+// exists, e.g. because a previous migration was interrupted.
+func (migrator RulesetMigrator) finishMigration(group *migrationGroup, protections map[string]*github.Protection, fix bool) {
+	for _, branch := range sortedKeysOfProtections(protections) {
+		if fix {
+			migrator.removeClassicProtection(branch)
+			fmt.Printf("Removed the classic branch protection of exasol/%v/%v that was already migrated to ruleset '%v'.\n", migrator.repoName, branch, group.rulesetName)
+		} else {
+			fmt.Printf("exasol/%v/%v was already migrated to ruleset '%v' but still has its classic branch protection. Use --fix to remove it.\n", migrator.repoName, branch, group.rulesetName)
+		}
+	}
+}
+
+func (migrator RulesetMigrator) replaceProtectionsByRuleset(group *migrationGroup, protections map[string]*github.Protection, ruleset *github.Ruleset) {
+	FixRulesetProblemHandler{migrator.client}.createRuleset(migrator.repoName, ruleset)
+	for _, branch := range sortedKeysOfProtections(protections) {
+		migrator.removeClassicProtection(branch)
+	}
+}
+
+func (migrator RulesetMigrator) removeClassicProtection(branch string) {
+	_, err := migrator.client.Repositories.RemoveBranchProtection(context.Background(), "exasol", migrator.repoName, branch)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to remove the classic branch protection of exasol/%v/%v. Cause: %v", migrator.repoName, branch, err.Error()))
+	}
+}
+
+func sortedKeysOfProtections(protections map[string]*github.Protection) []string {
+	keys := make([]string, 0, len(protections))
+	for key := range protections {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (migrator RulesetMigrator) listProtectedBranches() []string {
+	var result []string
+	protected := true
+	options := &github.BranchListOptions{Protected: &protected, ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		branches, response, err := migrator.client.Repositories.ListBranches(context.Background(), "exasol", migrator.repoName, options)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to list protected branches of repository exasol/%v. Cause: %v", migrator.repoName, err.Error()))
+		}
+		for _, branch := range branches {
+			result = append(result, branch.GetName())
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+	return result
+}
+
+// convertProtectionToRuleset creates a ruleset that enforces the same rules as the given classic branch protection.
+func convertProtectionToRuleset(name string, refs []string, protection *github.Protection) *github.Ruleset {
+	var rules []*github.RepositoryRule
+	if protection.AllowDeletions == nil || !protection.AllowDeletions.Enabled {
+		rules = append(rules, github.NewDeletionRule())
+	}
+	if protection.AllowForcePushes == nil || !protection.AllowForcePushes.Enabled {
+		rules = append(rules, github.NewNonFastForwardRule())
+	}
+	if protection.RequireLinearHistory != nil && protection.RequireLinearHistory.Enabled {
+		rules = append(rules, github.NewRequiredLinearHistoryRule())
+	}
+	if protection.BlockCreations != nil && protection.BlockCreations.GetEnabled() {
+		rules = append(rules, github.NewCreationRule())
+	}
+	if protection.LockBranch != nil && protection.LockBranch.GetEnabled() {
+		rules = append(rules, github.NewUpdateRule(&github.UpdateAllowsFetchAndMergeRuleParameters{}))
+	}
+	if protection.RequiredSignatures != nil && protection.RequiredSignatures.GetEnabled() {
+		rules = append(rules, github.NewRequiredSignaturesRule())
+	}
+	// Rulesets only require conversation resolution as part of the pull request rule, so the rule is also needed if
+	// the classic protection requires conversation resolution without reviews.
+	conversationResolution := protection.RequiredConversationResolution != nil && protection.RequiredConversationResolution.Enabled
+	if reviews := protection.RequiredPullRequestReviews; reviews != nil || conversationResolution {
+		parameters := &github.PullRequestRuleParameters{RequiredReviewThreadResolution: conversationResolution}
+		if reviews != nil {
+			parameters.DismissStaleReviewsOnPush = reviews.DismissStaleReviews
+			parameters.RequireCodeOwnerReview = reviews.RequireCodeOwnerReviews
+			parameters.RequireLastPushApproval = reviews.RequireLastPushApproval
+			parameters.RequiredApprovingReviewCount = reviews.RequiredApprovingReviewCount
+		}
+		rules = append(rules, github.NewPullRequestRule(parameters))
+	}
+	if statusChecks := protection.RequiredStatusChecks; statusChecks != nil {
+		rules = append(rules, github.NewRequiredStatusChecksRule(convertRequiredStatusChecks(statusChecks)))
+	}
+	enforceAdmins := protection.EnforceAdmins != nil && protection.EnforceAdmins.Enabled
+	return createRuleset(name, refs, rules, enforceAdmins)
+}
+
+// findUnsupportedSettings returns the settings of the classic branch protection that convertProtectionToRuleset can't
+// convert.
+func findUnsupportedSettings(protection *github.Protection) []string {
+	var result []string
+	if protection.Restrictions != nil {
+		result = append(result, "push restrictions")
+	}
+	if reviews := protection.RequiredPullRequestReviews; reviews != nil {
+		if allowances := reviews.BypassPullRequestAllowances; allowances != nil && len(allowances.Users)+len(allowances.Teams)+len(allowances.Apps) > 0 {
+			result = append(result, "pull request bypass allowances")
+		}
+		if restrictions := reviews.DismissalRestrictions; restrictions != nil && len(restrictions.Users)+len(restrictions.Teams)+len(restrictions.Apps) > 0 {
+			result = append(result, "review dismissal restrictions")
+		}
+	}
+	return result
+}
+
+func convertRequiredStatusChecks(statusChecks *github.RequiredStatusChecks) *github.RequiredStatusChecksRuleParameters {
+	existingChecks := getExistingStatusChecks(statusChecks)
+	checks := make([]github.RuleRequiredStatusChecks, 0, len(existingChecks))
+	for _, check := range existingChecks {
+		checks = append(checks, github.RuleRequiredStatusChecks{Context: check.Context, IntegrationID: check.AppID})
+	}
+	return &github.RequiredStatusChecksRuleParameters{RequiredStatusChecks: checks, StrictRequiredStatusChecksPolicy: statusChecks.Strict}
+}
+
+func init() {
+	migrateToRulesetsCmd.Flags().Bool("fix", false, "If this flag is set, github-keeper migrates the branch protections. Otherwise it just prints them.")
+	migrateToRulesetsCmd.Flags().Bool("drop-unsupported-settings", false, "Migrate branch protections with push restrictions, bypass allowances or dismissal restrictions although rulesets can't express them.")
+	migrateToRulesetsCmd.Flags().String("policy", getDefaultPolicyFile(), "Use a different policy file location")
+	rootCmd.AddCommand(migrateToRulesetsCmd)
+}
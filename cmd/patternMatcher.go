@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// matchesPattern checks if a value matches a pattern in GitHub's filter pattern syntax.
+// '*' matches any characters except '/', '**' matches any characters, '?' matches a single character and '[...]'
+// matches a character range. All other characters are literals.
+func matchesPattern(pattern string, value string) bool {
+	compiledPattern, err := regexp.Compile(convertPatternToRegex(pattern))
+	if err != nil {
+		panic(fmt.Sprintf("Invalid pattern '%v'. Cause: %v", pattern, err.Error()))
+	}
+	return compiledPattern.MatchString(value)
+}
+
+func matchesAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchesPattern(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func convertPatternToRegex(pattern string) string {
+	var regex strings.Builder
+	regex.WriteString("^")
+	runes := []rune(pattern)
+	for index := 0; index < len(runes); index++ {
+		character := runes[index]
+		switch character {
+		case '*':
+			if index+1 < len(runes) && runes[index+1] == '*' {
+				regex.WriteString(".*")
+				index++
+			} else {
+				regex.WriteString("[^/]*")
+			}
+		case '?':
+			regex.WriteString(".")
+		case '[':
+			end := findClosingBracket(runes, index)
+			if end < 0 {
+				regex.WriteString(regexp.QuoteMeta(string(character)))
+			} else {
+				regex.WriteString(string(runes[index : end+1]))
+				index = end
+			}
+		default:
+			regex.WriteString(regexp.QuoteMeta(string(character)))
+		}
+	}
+	regex.WriteString("$")
+	return regex.String()
+}
+
+func findClosingBracket(runes []rune, start int) int {
+	for index := start + 1; index < len(runes); index++ {
+		if runes[index] == ']' {
+			return index
+		}
+	}
+	return -1
+}
+
+// matchesFilterPatterns checks if a value matches a list of filter patterns in which patterns starting with '!' exclude
+// values again. Like in GitHub's workflow filters the last matching pattern decides.
+func matchesFilterPatterns(patterns []string, value string) bool {
+	matches := false
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			if matchesPattern(strings.TrimPrefix(pattern, "!"), value) {
+				matches = false
+			}
+		} else if matchesPattern(pattern, value) {
+			matches = true
+		}
+	}
+	return matches
+}
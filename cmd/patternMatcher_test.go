@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PatternMatcherSuite struct {
+	suite.Suite
+}
+
+func TestPatternMatcherSuite(t *testing.T) {
+	suite.Run(t, new(PatternMatcherSuite))
+}
+
+func (suite *PatternMatcherSuite) TestExactMatch() {
+	suite.Assert().True(matchesPattern("main", "main"))
+	suite.Assert().False(matchesPattern("main", "main2"))
+}
+
+func (suite *PatternMatcherSuite) TestSingleStarDoesNotMatchSlash() {
+	suite.Assert().True(matchesPattern("release/*", "release/1.0"))
+	suite.Assert().False(matchesPattern("release/*", "release/1.0/hotfix"))
+}
+
+func (suite *PatternMatcherSuite) TestDoubleStarMatchesSlash() {
+	suite.Assert().True(matchesPattern("release/**", "release/1.0/hotfix"))
+}
+
+func (suite *PatternMatcherSuite) TestSpecialCharactersAreLiterals() {
+	suite.Assert().True(matchesPattern("v1.x", "v1.x"))
+	suite.Assert().False(matchesPattern("v1.x", "v12x"))
+}
+
+func (suite *PatternMatcherSuite) TestQuestionMarkMatchesSingleCharacter() {
+	suite.Assert().True(matchesPattern("v?.x", "v1.x"))
+	suite.Assert().False(matchesPattern("v?.x", "v.x"))
+	suite.Assert().True(matchesPattern("?ain", "main"))
+}
+
+func (suite *PatternMatcherSuite) TestPlusIsLiteral() {
+	suite.Assert().True(matchesPattern("c++", "c++"))
+	suite.Assert().False(matchesPattern("c++", "ccc"))
+}
+
+func (suite *PatternMatcherSuite) TestInvalidPatternPanics() {
+	suite.Panics(func() { matchesPattern("v[z-a]", "v1") })
+}
+
+func (suite *PatternMatcherSuite) TestCharacterRange() {
+	suite.Assert().True(matchesPattern("v[0-9].x", "v1.x"))
+	suite.Assert().False(matchesPattern("v[0-9].x", "va.x"))
+}
+
+func (suite *PatternMatcherSuite) TestMatchesAnyPattern() {
+	suite.Assert().True(matchesAnyPattern([]string{"area:*", "connector:*"}, "connector:oracle"))
+	suite.Assert().False(matchesAnyPattern([]string{"area:*", "connector:*"}, "feature"))
+}
+
+func (suite *PatternMatcherSuite) TestFilterPatternsWithNegation() {
+	patterns := []string{"release/**", "!release/**-alpha", "release/1.0-alpha"}
+	suite.Assert().True(matchesFilterPatterns(patterns, "release/2.0"))
+	suite.Assert().False(matchesFilterPatterns(patterns, "release/2.0-alpha"))
+	suite.Assert().True(matchesFilterPatterns(patterns, "release/1.0-alpha"))
+	suite.Assert().False(matchesFilterPatterns(patterns, "main"))
+}
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/go-github/v57/github"
+)
+
+const missingAccessFinding = "missing-access"
+const extraAccessFinding = "extra-access"
+const overPrivilegedAccessFinding = "over-privileged-access"
+const insufficientAccessFinding = "insufficient-access"
+
+// noAccess is the permission of teams and collaborators that have no access to the repository.
+const noAccess = "none"
+const adminPermission = "admin"
+
+// permissionLevels contains the permissions of repositories from the lowest to the highest.
+var permissionLevels = []string{"read", "triage", "write", "maintain", adminPermission}
+
+// normalizePermission converts the permission names of the GitHub API ("pull", "push") to the names of the GitHub UI.
+func normalizePermission(permission string) string {
+	switch permission {
+	case "pull":
+		return "read"
+	case "push":
+		return "write"
+	default:
+		return permission
+	}
+}
+
+// toApiPermission converts the permission to the name the GitHub API expects when granting it.
+func toApiPermission(permission string) string {
+	switch permission {
+	case "read":
+		return "pull"
+	case "write":
+		return "push"
+	default:
+		return permission
+	}
+}
+
+func permissionRank(permission string) int {
+	if permission == noAccess {
+		return -1
+	}
+	for index, level := range permissionLevels {
+		if level == permission {
+			return index
+		}
+	}
+	panic(fmt.Sprintf("Unsupported permission '%v'. Supported permissions are %v.", permission, formatList(permissionLevels)))
+}
+
+// isSupportedPermission checks if the permission is one of the permission levels. It also accepts the names of the
+// GitHub API.
+func isSupportedPermission(permission string) bool {
+	normalized := normalizePermission(permission)
+	for _, level := range permissionLevels {
+		if level == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the permission names of the policy, so that a typo fails before github-keeper changes any repository.
+func (policy *PermissionsPolicy) validate() {
+	for _, team := range sortedKeys(policy.Teams) {
+		if !isSupportedPermission(policy.Teams[team]) {
+			panic(fmt.Sprintf("The permissions policy contains the unsupported permission '%v' for team '%v'. Supported permissions are %v.",
+				policy.Teams[team], team, formatList(permissionLevels)))
+		}
+	}
+	maxPermission := policy.MaxOutsideCollaboratorPermission
+	if maxPermission != "" && maxPermission != noAccess && !isSupportedPermission(maxPermission) {
+		panic(fmt.Sprintf("The permissions policy contains the unsupported maxOutsideCollaboratorPermission '%v'. Supported permissions are %v and '%v'.",
+			maxPermission, formatList(permissionLevels), noAccess))
+	}
+}
+
+// getHighestPermission returns the highest permission of the permissions map of a team or collaborator. It also
+// supports custom roles that are based on the standard permissions.
+func getHighestPermission(permissions map[string]bool) string {
+	result := noAccess
+	for _, level := range permissionLevels {
+		if permissions[level] || permissions[toApiPermission(level)] {
+			result = level
+		}
+	}
+	return result
+}
+
+// accessGrant is the access of a team or collaborator that differs from the policy.
+type accessGrant struct {
+	team         string
+	collaborator string
+	expected     string
+	actual       string
+}
+
+func (grant accessGrant) subject() string {
+	if grant.team != "" {
+		return fmt.Sprintf("team '%v'", grant.team)
+	}
+	return fmt.Sprintf("collaborator '%v'", grant.collaborator)
+}
+
+func (grant accessGrant) String() string {
+	return fmt.Sprintf("%v: expected %v, actual %v", grant.subject(), grant.expected, grant.actual)
+}
+
+func (grant accessGrant) kind() string {
+	switch {
+	case grant.actual == noAccess:
+		return missingAccessFinding
+	case grant.expected == noAccess:
+		return extraAccessFinding
+	case permissionRank(grant.expected) < permissionRank(grant.actual):
+		return overPrivilegedAccessFinding
+	default:
+		return insufficientAccessFinding
+	}
+}
+
+// reducesAdminAccess checks if applying the grant takes admin access away.
+func (grant accessGrant) reducesAdminAccess() bool {
+	return grant.actual == adminPermission && grant.expected != adminPermission
+}
+
+// diffTeamAccess compares the permissions of the teams (slug to permission) with the policy.
+func diffTeamAccess(policy *PermissionsPolicy, existing map[string]string) []accessGrant {
+	var grants []accessGrant
+	for _, team := range sortedKeys(policy.Teams) {
+		expected := normalizePermission(policy.Teams[team])
+		actual, found := existing[team]
+		if !found {
+			actual = noAccess
+		}
+		if actual != expected {
+			grants = append(grants, accessGrant{team: team, expected: expected, actual: actual})
+		}
+	}
+	if !policy.AllowOtherTeams {
+		for _, team := range sortedKeys(existing) {
+			if _, found := policy.Teams[team]; !found {
+				grants = append(grants, accessGrant{team: team, expected: noAccess, actual: existing[team]})
+			}
+		}
+	}
+	return grants
+}
+
+// diffOutsideCollaboratorAccess lists the outside collaborators (login to permission) whose permission is higher than
+// the maximum permission.
+func diffOutsideCollaboratorAccess(maxPermission string, existing map[string]string) []accessGrant {
+	maxPermission = normalizePermission(maxPermission)
+	var grants []accessGrant
+	for _, login := range sortedKeys(existing) {
+		if permissionRank(existing[login]) > permissionRank(maxPermission) {
+			grants = append(grants, accessGrant{collaborator: login, expected: maxPermission, actual: existing[login]})
+		}
+	}
+	return grants
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PermissionsVerifier verifies which teams and outside collaborators have access to a repository.
+type PermissionsVerifier struct {
+	client   *github.Client
+	repoName string
+	policy   *PermissionsPolicy
+	// report collects the findings. It may be nil.
+	report *findingsReport
+}
+
+func (verifier PermissionsVerifier) VerifyPermissions(fix bool) {
+	var grants []accessGrant
+	if len(verifier.policy.Teams) > 0 {
+		grants = append(grants, diffTeamAccess(verifier.policy, verifier.listTeamPermissions())...)
+	}
+	if verifier.policy.MaxOutsideCollaboratorPermission != "" {
+		grants = append(grants, diffOutsideCollaboratorAccess(verifier.policy.MaxOutsideCollaboratorPermission, verifier.listOutsideCollaboratorPermissions())...)
+	}
+	if len(grants) == 0 {
+		return
+	}
+	for _, grant := range grants {
+		finding := Finding{Kind: grant.kind(), Repo: verifier.repoName, Attribute: grant.subject(), Expected: grant.expected,
+			Actual: grant.actual, Message: grant.String()}
+		printFindingWarning(finding)
+		verifier.report.add(finding)
+	}
+	if fix {
+		caller := verifier.getCallerLogin()
+		for _, grant := range grants {
+			if grant.reducesAdminAccess() && verifier.isCallerAccess(grant, caller) {
+				fmt.Printf("Skipping %v to keep your own admin access to exasol/%v.\n", grant.subject(), verifier.repoName)
+				continue
+			}
+			verifier.applyGrant(grant)
+		}
+	}
+}
+
+func (verifier PermissionsVerifier) listTeamPermissions() map[string]string {
+	result := map[string]string{}
+	options := &github.ListOptions{PerPage: 100}
+	for {
+		teams, response, err := verifier.client.Repositories.ListTeams(context.Background(), "exasol", verifier.repoName, options)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to list teams of repository exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+		}
+		for _, team := range teams {
+			result[team.GetSlug()] = normalizePermission(team.GetPermission())
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+	return result
+}
+
+func (verifier PermissionsVerifier) listOutsideCollaboratorPermissions() map[string]string {
+	result := map[string]string{}
+	options := &github.ListCollaboratorsOptions{Affiliation: "outside", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		collaborators, response, err := verifier.client.Repositories.ListCollaborators(context.Background(), "exasol", verifier.repoName, options)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to list outside collaborators of repository exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+		}
+		for _, collaborator := range collaborators {
+			result[collaborator.GetLogin()] = getHighestPermission(collaborator.GetPermissions())
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+	return result
+}
+
+func (verifier PermissionsVerifier) getCallerLogin() string {
+	user, _, err := verifier.client.Users.Get(context.Background(), "")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get the authenticated user. Cause: %v", err.Error()))
+	}
+	return user.GetLogin()
+}
+
+// isCallerAccess checks if the grant is the access of the authenticated user, either directly or by a team.
+func (verifier PermissionsVerifier) isCallerAccess(grant accessGrant, caller string) bool {
+	if grant.team == "" {
+		return grant.collaborator == caller
+	}
+	membership, response, err := verifier.client.Teams.GetTeamMembershipBySlug(context.Background(), "exasol", grant.team, caller)
+	if response != nil && response.StatusCode == 404 {
+		return false
+	} else if err != nil {
+		panic(fmt.Sprintf("Failed to get membership of %v in team exasol/%v. Cause: %v", caller, grant.team, err.Error()))
+	}
+	return membership.GetState() == "active"
+}
+
+func (verifier PermissionsVerifier) applyGrant(grant accessGrant) {
+	var err error
+	switch {
+	case grant.team != "" && grant.expected == noAccess:
+		_, err = verifier.client.Teams.RemoveTeamRepoBySlug(context.Background(), "exasol", grant.team, "exasol", verifier.repoName)
+	case grant.team != "":
+		_, err = verifier.client.Teams.AddTeamRepoBySlug(context.Background(), "exasol", grant.team, "exasol", verifier.repoName,
+			&github.TeamAddTeamRepoOptions{Permission: toApiPermission(grant.expected)})
+	case grant.expected == noAccess:
+		_, err = verifier.client.Repositories.RemoveCollaborator(context.Background(), "exasol", verifier.repoName, grant.collaborator)
+	default:
+		_, _, err = verifier.client.Repositories.AddCollaborator(context.Background(), "exasol", verifier.repoName, grant.collaborator,
+			&github.RepositoryAddCollaboratorOptions{Permission: toApiPermission(grant.expected)})
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Failed to update access of %v to exasol/%v. Cause: %v", grant.subject(), verifier.repoName, err.Error()))
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PermissionsSuite struct {
+	suite.Suite
+}
+
+func TestPermissionsSuite(t *testing.T) {
+	suite.Run(t, new(PermissionsSuite))
+}
+
+func (suite *PermissionsSuite) TestCompliantTeams() {
+	policy := PermissionsPolicy{Teams: map[string]string{"integration-team": "maintain", "readers": "pull"}}
+	suite.Empty(diffTeamAccess(&policy, map[string]string{"integration-team": "maintain", "readers": "read"}))
+}
+
+func (suite *PermissionsSuite) TestTeamDifferences() {
+	policy := PermissionsPolicy{Teams: map[string]string{"admins": "admin", "integration-team": "maintain", "qa": "triage"}}
+	grants := diffTeamAccess(&policy, map[string]string{"integration-team": "admin", "qa": "read", "interns": "write"})
+	suite.Equal([]accessGrant{
+		{team: "admins", expected: "admin", actual: "none"},
+		{team: "integration-team", expected: "maintain", actual: "admin"},
+		{team: "qa", expected: "triage", actual: "read"},
+		{team: "interns", expected: "none", actual: "write"},
+	}, grants)
+	suite.Equal([]string{missingAccessFinding, overPrivilegedAccessFinding, insufficientAccessFinding, extraAccessFinding},
+		[]string{grants[0].kind(), grants[1].kind(), grants[2].kind(), grants[3].kind()})
+}
+
+func (suite *PermissionsSuite) TestAllowOtherTeams() {
+	policy := PermissionsPolicy{Teams: map[string]string{"admins": "admin"}, AllowOtherTeams: true}
+	suite.Empty(diffTeamAccess(&policy, map[string]string{"admins": "admin", "interns": "write"}))
+}
+
+func (suite *PermissionsSuite) TestOutsideCollaborators() {
+	grants := diffOutsideCollaboratorAccess("read", map[string]string{"alice": "write", "bob": "read", "carol": "admin"})
+	suite.Equal([]accessGrant{
+		{collaborator: "alice", expected: "read", actual: "write"},
+		{collaborator: "carol", expected: "read", actual: "admin"},
+	}, grants)
+	suite.Equal("collaborator 'carol': expected read, actual admin", grants[1].String())
+}
+
+func (suite *PermissionsSuite) TestNoOutsideCollaboratorsAllowed() {
+	grants := diffOutsideCollaboratorAccess("none", map[string]string{"alice": "read"})
+	suite.Equal([]accessGrant{{collaborator: "alice", expected: "none", actual: "read"}}, grants)
+	suite.Equal(extraAccessFinding, grants[0].kind())
+}
+
+func (suite *PermissionsSuite) TestValidPolicy() {
+	policy := PermissionsPolicy{Teams: map[string]string{"integration": "maintain", "qa": "pull"}, MaxOutsideCollaboratorPermission: "none"}
+	suite.NotPanics(policy.validate)
+}
+
+func (suite *PermissionsSuite) TestUnsupportedTeamPermissionIsInvalid() {
+	policy := PermissionsPolicy{Teams: map[string]string{"integration": "writ"}}
+	suite.PanicsWithValue("The permissions policy contains the unsupported permission 'writ' for team 'integration'. Supported permissions are 'read', 'triage', 'write', 'maintain', 'admin'.", policy.validate)
+}
+
+func (suite *PermissionsSuite) TestUnsupportedCollaboratorPermissionIsInvalid() {
+	policy := PermissionsPolicy{MaxOutsideCollaboratorPermission: "owner"}
+	suite.Panics(policy.validate)
+}
+
+func (suite *PermissionsSuite) TestHighestPermission() {
+	suite.Equal("maintain", getHighestPermission(map[string]bool{"pull": true, "triage": true, "push": true, "maintain": true, "admin": false}))
+	suite.Equal("none", getHighestPermission(map[string]bool{}))
+}
+
+func (suite *PermissionsSuite) TestReducesAdminAccess() {
+	suite.True(accessGrant{team: "admins", expected: "none", actual: "admin"}.reducesAdminAccess())
+	suite.True(accessGrant{collaborator: "me", expected: "write", actual: "admin"}.reducesAdminAccess())
+	suite.False(accessGrant{team: "qa", expected: "none", actual: "write"}.reducesAdminAccess())
+}
+
+func (suite *PermissionsSuite) TestUnsupportedPermissionPanics() {
+	suite.Panics(func() { diffOutsideCollaboratorAccess("owner", map[string]string{"alice": "write"}) })
+}
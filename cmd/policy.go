@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy describes the standards that github-keeper verifies and enforces. Every section that is not defined in the
+// policy file keeps its default value.
+type Policy struct {
+	Labels           LabelPolicy            `yaml:"labels"`
+	BranchProtection BranchProtectionPolicy `yaml:"branchProtection"`
+	CodeOwners       CodeOwnersPolicy       `yaml:"codeOwners"`
+	RepoSettings     RepoSettingsPolicy     `yaml:"repoSettings"`
+	Dependabot       DependabotPolicy       `yaml:"dependabot"`
+	// Files contains the files that repositories must contain.
+	Files       []RequiredFile    `yaml:"files"`
+	Permissions PermissionsPolicy `yaml:"permissions"`
+	Actions     ActionsPolicy     `yaml:"actions"`
+}
+
+// LabelPolicy describes how unifyLabels treats labels that are not part of the label definitions.
+type LabelPolicy struct {
+	// Protected contains patterns of labels that are left untouched.
+	Protected []string `yaml:"protected"`
+	// ColorFamilies contains label prefixes for which github-keeper only manages the color.
+	ColorFamilies []LabelColorFamily `yaml:"colorFamilies"`
+}
+
+type LabelColorFamily struct {
+	Prefix string `yaml:"prefix"`
+	Color  string `yaml:"color"`
+}
+
+// CodeOwnersPolicy describes the CODEOWNERS file that github-keeper proposes for repositories that require code owner
+// reviews but have no valid CODEOWNERS file.
+type CodeOwnersPolicy struct {
+	// Template is the content of the proposed CODEOWNERS file. If it is empty, github-keeper only reports the problems.
+	Template string `yaml:"template"`
+}
+
+// ActionsPolicy describes the GitHub Actions settings of repositories. Settings that are not defined (nil) are not
+// managed by github-keeper.
+type ActionsPolicy struct {
+	Enabled *bool `yaml:"enabled"`
+	// AllowedActions is "all", "local_only" or "selected".
+	AllowedActions *string `yaml:"allowedActions"`
+	// SelectedActions are the actions that are allowed if AllowedActions is "selected".
+	SelectedActions SelectedActionsPolicy `yaml:"selectedActions"`
+	// DefaultWorkflowPermissions are the default permissions of the GITHUB_TOKEN: "read" or "write".
+	DefaultWorkflowPermissions *string `yaml:"defaultWorkflowPermissions"`
+	// CanApprovePullRequestReviews allows workflows to approve pull requests.
+	CanApprovePullRequestReviews *bool `yaml:"canApprovePullRequestReviews"`
+}
+
+type SelectedActionsPolicy struct {
+	GithubOwnedAllowed *bool `yaml:"githubOwnedAllowed"`
+	VerifiedAllowed    *bool `yaml:"verifiedAllowed"`
+	// PatternsAllowed contains patterns of allowed actions, e.g. "exasol/*".
+	PatternsAllowed []string `yaml:"patternsAllowed"`
+}
+
+// PermissionsPolicy describes who has access to repositories. Permissions are "read", "triage", "write", "maintain"
+// or "admin".
+type PermissionsPolicy struct {
+	// Teams contains the permission of each team (slug). If it is empty, github-keeper does not verify teams.
+	Teams map[string]string `yaml:"teams"`
+	// AllowOtherTeams keeps the access of teams that are not listed in Teams. Otherwise, github-keeper removes them.
+	AllowOtherTeams bool `yaml:"allowOtherTeams"`
+	// MaxOutsideCollaboratorPermission is the highest permission of collaborators that are not members of the
+	// organization. "none" removes all outside collaborators. If it is empty, github-keeper does not verify outside
+	// collaborators.
+	MaxOutsideCollaboratorPermission string `yaml:"maxOutsideCollaboratorPermission"`
+}
+
+// RequiredFile describes a file that repositories must contain.
+type RequiredFile struct {
+	// Path is the path of the file relative to the repository root.
+	Path      string `yaml:"path"`
+	MustExist bool   `yaml:"mustExist"`
+	// Template is the content of the file that github-keeper proposes if the file is missing. "{{.Repo}}" is replaced
+	// by the name of the repository.
+	Template string `yaml:"template"`
+	// Pattern is a regular expression that the content of an existing file must match.
+	Pattern string `yaml:"pattern"`
+}
+
+// DependabotPolicy describes the expected version updates in the Dependabot configuration.
+type DependabotPolicy struct {
+	// Interval is the schedule of the version updates, e.g. "weekly". An empty interval disables the verification.
+	Interval string `yaml:"interval"`
+}
+
+// RepoSettingsPolicy describes the expected settings of a repository. Settings that are not defined (nil) are not
+// managed by github-keeper.
+type RepoSettingsPolicy struct {
+	AllowAutoMerge      *bool `yaml:"allowAutoMerge"`
+	DeleteBranchOnMerge *bool `yaml:"deleteBranchOnMerge"`
+	AllowSquashMerge    *bool `yaml:"allowSquashMerge"`
+	AllowMergeCommit    *bool `yaml:"allowMergeCommit"`
+	AllowRebaseMerge    *bool `yaml:"allowRebaseMerge"`
+	// AllowUpdateBranch shows the button to update the branch of a pull request with the base branch.
+	AllowUpdateBranch *bool `yaml:"allowUpdateBranch"`
+	// SquashMergeCommitTitle is the default title of squash commits: "PR_TITLE" or "COMMIT_OR_PR_TITLE".
+	SquashMergeCommitTitle *string `yaml:"squashMergeCommitTitle"`
+	// SquashMergeCommitMessage is the default message of squash commits: "PR_BODY", "COMMIT_MESSAGES" or "BLANK".
+	SquashMergeCommitMessage *string `yaml:"squashMergeCommitMessage"`
+	HasWiki                  *bool   `yaml:"hasWiki"`
+	HasProjects              *bool   `yaml:"hasProjects"`
+	HasIssues                *bool   `yaml:"hasIssues"`
+	HasDiscussions           *bool   `yaml:"hasDiscussions"`
+	// WebCommitSignoffRequired requires contributors to sign off commits made in the web interface.
+	WebCommitSignoffRequired *bool `yaml:"webCommitSignoffRequired"`
+	// DefaultBranch is the expected name of the default branch. In fix mode github-keeper renames the default branch.
+	DefaultBranch *string                `yaml:"defaultBranch"`
+	Security      SecuritySettingsPolicy `yaml:"security"`
+}
+
+// SecuritySettingsPolicy describes the security features that repositories must enable. github-keeper never disables
+// a security feature, so false means that the feature is not required.
+type SecuritySettingsPolicy struct {
+	VulnerabilityAlerts bool `yaml:"vulnerabilityAlerts"`
+	// DependabotSecurityUpdates creates pull requests that update vulnerable dependencies.
+	DependabotSecurityUpdates bool `yaml:"dependabotSecurityUpdates"`
+	SecretScanning            bool `yaml:"secretScanning"`
+	// SecretScanningPushProtection rejects pushes that contain secrets. It requires secret scanning.
+	SecretScanningPushProtection bool `yaml:"secretScanningPushProtection"`
+	// PrivateVulnerabilityReporting allows security researchers to report vulnerabilities privately.
+	PrivateVulnerabilityReporting bool `yaml:"privateVulnerabilityReporting"`
+}
+
+const classicBranchProtectionBackend = "classic"
+const rulesetsBranchProtectionBackend = "rulesets"
+
+// defaultBranchPattern is a placeholder pattern that matches the default branch of a repository.
+const defaultBranchPattern = "$default"
+
+// BranchProtectionPolicy describes which branches must be protected and which protection template applies to them.
+type BranchProtectionPolicy struct {
+	// Backend selects how branches are protected: classic branch protection ("classic") or repository rulesets ("rulesets").
+	Backend string `yaml:"backend"`
+	// RulesetName is the prefix for the names of the rulesets managed by github-keeper.
+	RulesetName string `yaml:"rulesetName"`
+	// Branches contains the branch patterns. For each branch the first matching pattern is used.
+	Branches []BranchProtectionRule `yaml:"branches"`
+	// Templates contains the protection templates by name.
+	Templates map[string]ProtectionTemplate `yaml:"templates"`
+	// KeepChecks contains patterns of required checks that github-keeper keeps even if no workflow produces them,
+	// e.g. checks reported by external apps.
+	KeepChecks []string `yaml:"keepChecks"`
+	// SonarCheckName is the name of the check that SonarCloud reports. It is required for repositories that use
+	// SonarCloud. An empty name disables the SonarCloud check.
+	SonarCheckName string `yaml:"sonarCheckName"`
+	// SonarAppId is the ID of the GitHub app that must report the SonarCloud check. 0 accepts the app that recently
+	// reported it.
+	SonarAppId int64 `yaml:"sonarAppId"`
+}
+
+type BranchProtectionRule struct {
+	Pattern  string `yaml:"pattern"`
+	Template string `yaml:"template"`
+}
+
+type ProtectionTemplate struct {
+	RequiredApprovingReviewCount int  `yaml:"requiredApprovingReviewCount"`
+	DismissStaleReviews          bool `yaml:"dismissStaleReviews"`
+	RequireCodeOwnerReviews      bool `yaml:"requireCodeOwnerReviews"`
+	EnforceAdmins                bool `yaml:"enforceAdmins"`
+	AllowForcePushes             bool `yaml:"allowForcePushes"`
+	AllowDeletions               bool `yaml:"allowDeletions"`
+	RequireLinearHistory         bool `yaml:"requireLinearHistory"`
+	// RequireConversationResolution requires all review comments to be resolved before merging.
+	RequireConversationResolution bool `yaml:"requireConversationResolution"`
+	// BlockCreations blocks pushes that create branches matching the pattern of the branch.
+	BlockCreations bool `yaml:"blockCreations"`
+	// LockBranch makes the branch read-only.
+	LockBranch           bool `yaml:"lockBranch"`
+	RequireSignedCommits bool `yaml:"requireSignedCommits"`
+	// BypassPullRequestAllowances may merge without the required pull request reviews, e.g. release automation.
+	BypassPullRequestAllowances ProtectionActors `yaml:"bypassPullRequestAllowances"`
+	// DismissalRestrictions are the only actors that may dismiss reviews. If empty, everyone with write access may
+	// dismiss reviews.
+	DismissalRestrictions ProtectionActors `yaml:"dismissalRestrictions"`
+	// RequireStatusChecks defines if the checks of the workflows are required.
+	RequireStatusChecks bool `yaml:"requireStatusChecks"`
+}
+
+// ProtectionActors are users (logins), teams (slugs) and apps (slugs) that get special rights on a protected branch.
+type ProtectionActors struct {
+	Users []string `yaml:"users"`
+	Teams []string `yaml:"teams"`
+	Apps  []string `yaml:"apps"`
+}
+
+func (actors ProtectionActors) isEmpty() bool {
+	return len(actors.Users) == 0 && len(actors.Teams) == 0 && len(actors.Apps) == 0
+}
+
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Labels:           LabelPolicy{Protected: []string{}, ColorFamilies: []LabelColorFamily{}},
+		BranchProtection: defaultBranchProtectionPolicy(),
+		RepoSettings:     defaultRepoSettingsPolicy(),
+		Dependabot:       DependabotPolicy{Interval: "weekly"},
+		Files:            defaultRequiredFiles(),
+	}
+}
+
+func defaultRequiredFiles() []RequiredFile {
+	var files []RequiredFile
+	for _, path := range []string{"LICENSE", "README.md", "SECURITY.md", "doc/changes/changelog.md", ".gitignore"} {
+		files = append(files, RequiredFile{Path: path, MustExist: true})
+	}
+	return files
+}
+
+func defaultRepoSettingsPolicy() RepoSettingsPolicy {
+	allowAutoMerge := true
+	deleteBranchOnMerge := true
+	return RepoSettingsPolicy{AllowAutoMerge: &allowAutoMerge, DeleteBranchOnMerge: &deleteBranchOnMerge,
+		Security: SecuritySettingsPolicy{
+			VulnerabilityAlerts:           true,
+			DependabotSecurityUpdates:     true,
+			SecretScanning:                true,
+			SecretScanningPushProtection:  true,
+			PrivateVulnerabilityReporting: true,
+		},
+	}
+}
+
+func defaultBranchProtectionPolicy() BranchProtectionPolicy {
+	return BranchProtectionPolicy{
+		Backend:        classicBranchProtectionBackend,
+		RulesetName:    "github-keeper",
+		KeepChecks:     []string{},
+		SonarCheckName: defaultSonarCheckName,
+		SonarAppId:     sonarCloudAppId,
+		Branches:       []BranchProtectionRule{{Pattern: defaultBranchPattern, Template: "default"}},
+		Templates: map[string]ProtectionTemplate{
+			"default": {
+				RequiredApprovingReviewCount: 1,
+				DismissStaleReviews:          true,
+				RequireCodeOwnerReviews:      true,
+				EnforceAdmins:                true,
+				AllowForcePushes:             false,
+				RequireStatusChecks:          true,
+			},
+		},
+	}
+}
+
+// ReadPolicyFromYaml reads the policy file. If the file does not exist and is not required, it returns the default policy.
+func ReadPolicyFromYaml(yamlFile string, required bool) *Policy {
+	policy := DefaultPolicy()
+	file, err := os.Open(yamlFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return policy
+		}
+		panic(fmt.Sprintf("Failed to open policy file %v. Cause: %v.", yamlFile, err.Error()))
+	}
+	defer file.Close()
+	err = yaml.NewDecoder(bufio.NewReader(file)).Decode(policy)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse policy file %v. Cause %v.", yamlFile, err.Error()))
+	}
+	policy.Permissions.validate()
+	return policy
+}
+
+func getDefaultPolicyFile() string {
+	homedir, err := os.UserHomeDir()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get user's home directory. Cause: %v", err.Error()))
+	}
+	return path.Join(homedir, ".github-keeper", "policy.yml")
+}
+
+func (policy *LabelPolicy) isProtected(labelName string) bool {
+	return matchesAnyPattern(policy.Protected, labelName)
+}
+
+func (policy *LabelPolicy) findColorFamily(labelName string) *LabelColorFamily {
+	for index := range policy.ColorFamilies {
+		if strings.HasPrefix(labelName, policy.ColorFamilies[index].Prefix) {
+			return &policy.ColorFamilies[index]
+		}
+	}
+	return nil
+}
+
+// findTemplateForBranch returns the template of the first rule that matches the given branch or nil if no rule matches.
+func (policy *BranchProtectionPolicy) findTemplateForBranch(branch string, isDefaultBranch bool) *ProtectionTemplate {
+	rule := policy.findRuleForBranch(branch, isDefaultBranch)
+	if rule == nil {
+		return nil
+	}
+	template, found := policy.Templates[rule.Template]
+	if !found {
+		panic(fmt.Sprintf("The branch protection policy references the undefined template '%v'.", rule.Template))
+	}
+	return &template
+}
+
+// findRuleForBranch returns the first rule that matches the given branch or nil if no rule matches.
+func (policy *BranchProtectionPolicy) findRuleForBranch(branch string, isDefaultBranch bool) *BranchProtectionRule {
+	for index, rule := range policy.Branches {
+		if (rule.Pattern == defaultBranchPattern && isDefaultBranch) || matchesPattern(rule.Pattern, branch) {
+			return &policy.Branches[index]
+		}
+	}
+	return nil
+}
+
+// onlyProtectsDefaultBranch returns true if the policy does not contain patterns for other branches than the default branch.
+func (policy *BranchProtectionPolicy) onlyProtectsDefaultBranch() bool {
+	for _, rule := range policy.Branches {
+		if rule.Pattern != defaultBranchPattern {
+			return false
+		}
+	}
+	return true
+}
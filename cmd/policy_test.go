@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PolicySuite struct {
+	suite.Suite
+}
+
+func TestPolicySuite(t *testing.T) {
+	suite.Run(t, new(PolicySuite))
+}
+
+func (suite *PolicySuite) TestRead() {
+	policy := ReadPolicyFromYaml("../test_resources/policy.yml", true)
+	suite.Equal([]string{"connector:*"}, policy.Labels.Protected)
+	suite.Equal([]LabelColorFamily{{Prefix: "area:", Color: "1d76db"}}, policy.Labels.ColorFamilies)
+	suite.Len(policy.BranchProtection.Branches, 2)
+	suite.Equal(2, policy.BranchProtection.Templates["release"].RequiredApprovingReviewCount)
+	suite.Equal(ProtectionActors{Apps: []string{"release-droid"}}, policy.BranchProtection.Templates["release"].BypassPullRequestAllowances)
+	suite.Equal(ProtectionActors{Teams: []string{"maintainers"}}, policy.BranchProtection.Templates["release"].DismissalRestrictions)
+}
+
+func (suite *PolicySuite) TestReadRepoSettings() {
+	settings := ReadPolicyFromYaml("../test_resources/policy.yml", true).RepoSettings
+	suite.True(*settings.AllowAutoMerge)
+	suite.False(*settings.AllowMergeCommit)
+	suite.False(*settings.HasWiki)
+	suite.Equal("PR_TITLE", *settings.SquashMergeCommitTitle)
+	suite.Nil(settings.AllowRebaseMerge)
+	suite.Nil(settings.DefaultBranch)
+}
+
+func (suite *PolicySuite) TestReadRequiredFilesReplacesDefaults() {
+	files := ReadPolicyFromYaml("../test_resources/policy.yml", true).Files
+	suite.Equal([]RequiredFile{
+		{Path: "SECURITY.md", MustExist: true, Template: "# Security of {{.Repo}}"},
+		{Path: "README.md", Pattern: "(?m)^# "},
+	}, files)
+}
+
+func (suite *PolicySuite) TestSectionsMissingInFileKeepDefaults() {
+	policy := ReadPolicyFromYaml("../test_resources/policy_without_branch_protection.yml", true)
+	suite.Equal(defaultBranchProtectionPolicy(), policy.BranchProtection)
+}
+
+func (suite *PolicySuite) TestMissingOptionalFileReturnsDefault() {
+	suite.Equal(DefaultPolicy(), ReadPolicyFromYaml("../test_resources/non-existing-policy.yml", false))
+}
+
+func (suite *PolicySuite) TestMissingRequiredFilePanics() {
+	suite.Panics(func() {
+		ReadPolicyFromYaml("../test_resources/non-existing-policy.yml", true)
+	})
+}
+
+func (suite *PolicySuite) TestInvalidPermissionPanics() {
+	suite.Panics(func() {
+		ReadPolicyFromYaml("../test_resources/policy_with_invalid_permission.yml", true)
+	})
+	suite.Panics(func() {
+		ReadPolicyFromYaml("../test_resources/policy_with_invalid_collaborator_permission.yml", true)
+	})
+}
+
+func (suite *PolicySuite) TestLabelIsProtected() {
+	policy := LabelPolicy{Protected: []string{"connector:*"}}
+	suite.Assert().True(policy.isProtected("connector:oracle"))
+	suite.Assert().False(policy.isProtected("feature"))
+}
+
+func (suite *PolicySuite) TestFindColorFamily() {
+	policy := LabelPolicy{ColorFamilies: []LabelColorFamily{{Prefix: "area:", Color: "1d76db"}}}
+	suite.Equal("1d76db", policy.findColorFamily("area:udf").Color)
+	suite.Nil(policy.findColorFamily("connector:oracle"))
+}
+
+func (suite *PolicySuite) TestFindTemplateForDefaultBranch() {
+	policy := ReadPolicyFromYaml("../test_resources/policy.yml", true).BranchProtection
+	suite.Equal(1, policy.findTemplateForBranch("main", true).RequiredApprovingReviewCount)
+}
+
+func (suite *PolicySuite) TestFindTemplateForBranchPattern() {
+	policy := ReadPolicyFromYaml("../test_resources/policy.yml", true).BranchProtection
+	suite.Equal(2, policy.findTemplateForBranch("release/1.0", false).RequiredApprovingReviewCount)
+}
+
+func (suite *PolicySuite) TestFindTemplateForUnprotectedBranch() {
+	policy := ReadPolicyFromYaml("../test_resources/policy.yml", true).BranchProtection
+	suite.Nil(policy.findTemplateForBranch("feature/abc", false))
+}
+
+func (suite *PolicySuite) TestFindTemplateWithUndefinedTemplatePanics() {
+	policy := BranchProtectionPolicy{Branches: []BranchProtectionRule{{Pattern: "main", Template: "missing"}}}
+	suite.Panics(func() {
+		policy.findTemplateForBranch("main", true)
+	})
+}
+
+func (suite *PolicySuite) TestOnlyProtectsDefaultBranch() {
+	suite.Assert().True(DefaultPolicy().BranchProtection.onlyProtectsDefaultBranch())
+	policy := ReadPolicyFromYaml("../test_resources/policy.yml", true).BranchProtection
+	suite.Assert().False(policy.onlyProtectsDefaultBranch())
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+const protectionDriftFinding = "protection-drift"
+const requireSignedCommitsAttribute = "require signed commits"
+
+// protectionDifference is an attribute of an existing branch protection that differs from the expected protection.
+type protectionDifference struct {
+	attribute string
+	expected  string
+	actual    string
+}
+
+func (difference protectionDifference) String() string {
+	return fmt.Sprintf("%v: expected %v, actual %v", difference.attribute, difference.expected, difference.actual)
+}
+
+// toFinding converts the difference into a finding for the machine-readable report.
+func (difference protectionDifference) toFinding(repo string, branch string) Finding {
+	return Finding{Kind: protectionDriftFinding, Repo: repo, Branch: branch, Attribute: difference.attribute,
+		Expected: difference.expected, Actual: difference.actual, Message: difference.String()}
+}
+
+// diffBranchProtection compares the existing branch protection with the protection request attribute by attribute.
+// Required commit signatures are not part of the request and are compared separately. The stale checks are required by
+// the existing protection but not by the request.
+func diffBranchProtection(existing *github.Protection, request *github.ProtectionRequest, requireSignedCommits bool, staleChecks []string) []protectionDifference {
+	var differences []protectionDifference
+	differences = diffOptionalBool(differences, "allow force pushes", request.AllowForcePushes, existing.AllowForcePushes != nil && existing.AllowForcePushes.Enabled)
+	differences = diffOptionalBool(differences, "allow deletions", request.AllowDeletions, existing.AllowDeletions != nil && existing.AllowDeletions.Enabled)
+	differences = diffOptionalBool(differences, "require linear history", request.RequireLinearHistory, existing.RequireLinearHistory != nil && existing.RequireLinearHistory.Enabled)
+	differences = diffOptionalBool(differences, "require conversation resolution", request.RequiredConversationResolution,
+		existing.RequiredConversationResolution != nil && existing.RequiredConversationResolution.Enabled)
+	differences = diffOptionalBool(differences, "block creations", request.BlockCreations, existing.BlockCreations != nil && existing.BlockCreations.GetEnabled())
+	differences = diffOptionalBool(differences, "lock branch", request.LockBranch, existing.LockBranch != nil && existing.LockBranch.GetEnabled())
+	differences = diffBool(differences, requireSignedCommitsAttribute, requireSignedCommits, existing.RequiredSignatures != nil && existing.RequiredSignatures.GetEnabled())
+	differences = diffBool(differences, "enforce admins", request.EnforceAdmins, existing.EnforceAdmins != nil && existing.EnforceAdmins.Enabled)
+	differences = append(differences, diffPullRequestReviews(existing.RequiredPullRequestReviews, request.RequiredPullRequestReviews)...)
+	differences = append(differences, diffStatusChecks(existing.RequiredStatusChecks, request.RequiredStatusChecks, staleChecks)...)
+	return append(differences, diffRestrictions(existing.Restrictions, request.Restrictions)...)
+}
+
+func diffBool(differences []protectionDifference, attribute string, expected bool, actual bool) []protectionDifference {
+	if expected != actual {
+		differences = append(differences, protectionDifference{attribute: attribute, expected: fmt.Sprint(expected), actual: fmt.Sprint(actual)})
+	}
+	return differences
+}
+
+// diffOptionalBool compares an attribute of the request that is only managed if it is set.
+func diffOptionalBool(differences []protectionDifference, attribute string, expected *bool, actual bool) []protectionDifference {
+	if expected == nil {
+		return differences
+	}
+	return diffBool(differences, attribute, *expected, actual)
+}
+
+func containsDifference(differences []protectionDifference, attribute string) bool {
+	for _, difference := range differences {
+		if difference.attribute == attribute {
+			return true
+		}
+	}
+	return false
+}
+
+func diffPullRequestReviews(existing *github.PullRequestReviewsEnforcement, request *github.PullRequestReviewsEnforcementRequest) []protectionDifference {
+	if request == nil {
+		return nil
+	}
+	if existing == nil {
+		return []protectionDifference{{attribute: "pull request reviews", expected: "required", actual: "not required"}}
+	}
+	var differences []protectionDifference
+	if existing.RequiredApprovingReviewCount < request.RequiredApprovingReviewCount {
+		differences = append(differences, protectionDifference{attribute: "required approving review count",
+			expected: fmt.Sprintf("at least %d", request.RequiredApprovingReviewCount), actual: fmt.Sprint(existing.RequiredApprovingReviewCount)})
+	}
+	differences = diffBool(differences, "dismiss stale reviews", request.DismissStaleReviews, existing.DismissStaleReviews)
+	differences = diffBool(differences, "require code owner reviews", request.RequireCodeOwnerReviews, existing.RequireCodeOwnerReviews)
+	differences = diffBypassPullRequestAllowances(differences, existing.BypassPullRequestAllowances, request.BypassPullRequestAllowancesRequest)
+	return diffDismissalRestrictions(differences, existing.DismissalRestrictions, request.DismissalRestrictionsRequest)
+}
+
+func diffBypassPullRequestAllowances(differences []protectionDifference, existing *github.BypassPullRequestAllowances, request *github.BypassPullRequestAllowancesRequest) []protectionDifference {
+	expected := github.BypassPullRequestAllowancesRequest{}
+	if request != nil {
+		expected = *request
+	}
+	actual := github.BypassPullRequestAllowances{}
+	if existing != nil {
+		actual = *existing
+	}
+	differences = diffList(differences, "pull request bypass users", expected.Users, getUserLogins(actual.Users))
+	differences = diffList(differences, "pull request bypass teams", expected.Teams, getTeamSlugs(actual.Teams))
+	return diffList(differences, "pull request bypass apps", expected.Apps, getAppSlugs(actual.Apps))
+}
+
+func diffDismissalRestrictions(differences []protectionDifference, existing *github.DismissalRestrictions, request *github.DismissalRestrictionsRequest) []protectionDifference {
+	var expectedUsers, expectedTeams, expectedApps []string
+	if request != nil {
+		expectedUsers, expectedTeams, expectedApps = *request.Users, *request.Teams, *request.Apps
+	}
+	actual := github.DismissalRestrictions{}
+	if existing != nil {
+		actual = *existing
+	}
+	differences = diffList(differences, "review dismissal users", expectedUsers, getUserLogins(actual.Users))
+	differences = diffList(differences, "review dismissal teams", expectedTeams, getTeamSlugs(actual.Teams))
+	return diffList(differences, "review dismissal apps", expectedApps, getAppSlugs(actual.Apps))
+}
+
+// diffStatusChecks compares the required status checks. Stale checks are also reported if no workflow produces any
+// check anymore, e.g. because all workflows were deleted or renamed.
+func diffStatusChecks(existing *github.RequiredStatusChecks, request *github.RequiredStatusChecks, staleChecks []string) []protectionDifference {
+	if request == nil {
+		return diffStaleChecks(nil, staleChecks)
+	}
+	if existing == nil {
+		return []protectionDifference{{attribute: "required status checks", expected: formatList(getStatusCheckContexts(request.Checks)), actual: "none"}}
+	}
+	var differences []protectionDifference
+	differences = diffBool(differences, "require branches to be up to date", request.Strict, existing.Strict)
+	existingChecks := getExistingStatusChecks(existing)
+	var missingChecks []string
+	for _, requiredCheck := range request.Checks {
+		existingCheck := findStatusCheck(existingChecks, requiredCheck.Context)
+		if existingCheck == nil {
+			missingChecks = append(missingChecks, requiredCheck.Context)
+		} else if !isSameAppBinding(requiredCheck.AppID, existingCheck.AppID) {
+			differences = append(differences, protectionDifference{attribute: fmt.Sprintf("app of status check '%v'", requiredCheck.Context),
+				expected: describeAppBinding(requiredCheck.AppID), actual: describeAppBinding(existingCheck.AppID)})
+		}
+	}
+	if len(missingChecks) > 0 {
+		differences = append(differences, protectionDifference{attribute: "missing status checks", expected: formatList(missingChecks), actual: "not required"})
+	}
+	return diffStaleChecks(differences, staleChecks)
+}
+
+func diffStaleChecks(differences []protectionDifference, staleChecks []string) []protectionDifference {
+	if len(staleChecks) > 0 {
+		differences = append(differences, protectionDifference{attribute: "extra status checks", expected: "not required", actual: formatList(staleChecks)})
+	}
+	return differences
+}
+
+func diffRestrictions(existing *github.BranchRestrictions, request *github.BranchRestrictionsRequest) []protectionDifference {
+	if request == nil {
+		return nil
+	}
+	if existing == nil {
+		return []protectionDifference{{attribute: "push restrictions", expected: fmt.Sprintf("teams %v, users %v, apps %v",
+			formatList(request.Teams), formatList(request.Users), formatList(request.Apps)), actual: "none"}}
+	}
+	var differences []protectionDifference
+	differences = diffList(differences, "push restriction teams", request.Teams, getTeamSlugs(existing.Teams))
+	differences = diffList(differences, "push restriction users", request.Users, getUserLogins(existing.Users))
+	return diffList(differences, "push restriction apps", request.Apps, getAppSlugs(existing.Apps))
+}
+
+func diffList(differences []protectionDifference, attribute string, expected []string, actual []string) []protectionDifference {
+	if !stringSlicesEqualIgnoringOrder(expected, actual) {
+		differences = append(differences, protectionDifference{attribute: attribute, expected: formatList(expected), actual: formatList(actual)})
+	}
+	return differences
+}
+
+// formatList formats a list of names like "'a', 'b'" or "none" if the list is empty.
+func formatList(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return "'" + strings.Join(values, "', '") + "'"
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProtectionDiffSuite struct {
+	suite.Suite
+}
+
+func TestProtectionDiffSuite(t *testing.T) {
+	suite.Run(t, new(ProtectionDiffSuite))
+}
+
+func (suite *ProtectionDiffSuite) createRequest() *github.ProtectionRequest {
+	policy := defaultBranchProtectionPolicy()
+	branch := protectedBranch{name: "main", isDefault: true, template: policy.findTemplateForBranch("main", true)}
+	request := BranchProtectionVerifier{}.createProtectionRequest(branch, workflowChecks("build", "test"))
+	return &request
+}
+
+func (suite *ProtectionDiffSuite) createCompliantProtection() *github.Protection {
+	return &github.Protection{
+		AllowForcePushes: &github.AllowForcePushes{Enabled: false},
+		EnforceAdmins:    &github.AdminEnforcement{Enabled: true},
+		RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{
+			RequiredApprovingReviewCount: 2,
+			DismissStaleReviews:          true,
+			RequireCodeOwnerReviews:      true,
+		},
+		RequiredStatusChecks: &github.RequiredStatusChecks{Strict: true, Checks: suite.createActionsChecks("test", "build")},
+		Restrictions:         &github.BranchRestrictions{},
+	}
+}
+
+func (suite *ProtectionDiffSuite) createActionsChecks(contexts ...string) []*github.RequiredStatusCheck {
+	return toRequiredStatusChecks(workflowChecks(contexts...))
+}
+
+func (suite *ProtectionDiffSuite) TestCompliantProtection() {
+	suite.Empty(diffBranchProtection(suite.createCompliantProtection(), suite.createRequest(), false, nil))
+}
+
+func (suite *ProtectionDiffSuite) TestDifferentAttributes() {
+	existing := suite.createCompliantProtection()
+	existing.AllowForcePushes.Enabled = true
+	existing.EnforceAdmins = nil
+	existing.RequiredPullRequestReviews.RequiredApprovingReviewCount = 0
+	existing.RequiredPullRequestReviews.DismissStaleReviews = false
+	existing.RequiredStatusChecks = &github.RequiredStatusChecks{Strict: false, Checks: suite.createActionsChecks("build", "old")}
+	differences := diffBranchProtection(existing, suite.createRequest(), false, []string{"old"})
+	suite.Equal([]protectionDifference{
+		{attribute: "allow force pushes", expected: "false", actual: "true"},
+		{attribute: "enforce admins", expected: "true", actual: "false"},
+		{attribute: "required approving review count", expected: "at least 1", actual: "0"},
+		{attribute: "dismiss stale reviews", expected: "true", actual: "false"},
+		{attribute: "require branches to be up to date", expected: "true", actual: "false"},
+		{attribute: "missing status checks", expected: "'test'", actual: "not required"},
+		{attribute: "extra status checks", expected: "not required", actual: "'old'"},
+	}, differences)
+}
+
+func (suite *ProtectionDiffSuite) TestDifferentAdditionalAttributes() {
+	existing := suite.createCompliantProtection()
+	existing.AllowDeletions = &github.AllowDeletions{Enabled: true}
+	existing.RequireLinearHistory = &github.RequireLinearHistory{Enabled: true}
+	request := suite.createRequest()
+	requireConversationResolution := true
+	request.RequiredConversationResolution = &requireConversationResolution
+	differences := diffBranchProtection(existing, request, true, nil)
+	suite.Equal([]protectionDifference{
+		{attribute: "allow deletions", expected: "false", actual: "true"},
+		{attribute: "require linear history", expected: "false", actual: "true"},
+		{attribute: "require conversation resolution", expected: "true", actual: "false"},
+		{attribute: "require signed commits", expected: "true", actual: "false"},
+	}, differences)
+}
+
+func (suite *ProtectionDiffSuite) TestDifferentReviewAllowances() {
+	existing := suite.createCompliantProtection()
+	teamSlug := "maintainers"
+	existing.RequiredPullRequestReviews.DismissalRestrictions = &github.DismissalRestrictions{Teams: []*github.Team{{Slug: &teamSlug}}}
+	request := suite.createRequest()
+	request.RequiredPullRequestReviews.BypassPullRequestAllowancesRequest = createBypassPullRequestAllowancesRequest(ProtectionActors{Apps: []string{"dependabot"}})
+	differences := diffBranchProtection(existing, request, false, nil)
+	suite.Equal([]protectionDifference{
+		{attribute: "pull request bypass apps", expected: "'dependabot'", actual: "none"},
+		{attribute: "review dismissal teams", expected: "none", actual: "'maintainers'"},
+	}, differences)
+}
+
+func (suite *ProtectionDiffSuite) TestMatchingReviewAllowances() {
+	existing := suite.createCompliantProtection()
+	userLogin := "release-bot"
+	existing.RequiredPullRequestReviews.BypassPullRequestAllowances = &github.BypassPullRequestAllowances{Users: []*github.User{{Login: &userLogin}}}
+	existing.RequiredPullRequestReviews.DismissalRestrictions = &github.DismissalRestrictions{Users: []*github.User{{Login: &userLogin}}}
+	request := suite.createRequest()
+	actors := ProtectionActors{Users: []string{"release-bot"}}
+	request.RequiredPullRequestReviews.BypassPullRequestAllowancesRequest = createBypassPullRequestAllowancesRequest(actors)
+	request.RequiredPullRequestReviews.DismissalRestrictionsRequest = createDismissalRestrictionsRequest(actors)
+	suite.Empty(diffBranchProtection(existing, request, false, nil))
+}
+
+func (suite *ProtectionDiffSuite) TestContextOnlyChecksAreMigrated() {
+	existing := suite.createCompliantProtection()
+	existing.RequiredStatusChecks = &github.RequiredStatusChecks{Strict: true, Contexts: []string{"build", "test"}}
+	differences := diffBranchProtection(existing, suite.createRequest(), false, nil)
+	suite.Equal([]protectionDifference{
+		{attribute: "app of status check 'build'", expected: "app 15368 (GitHub Actions)", actual: "any app"},
+		{attribute: "app of status check 'test'", expected: "app 15368 (GitHub Actions)", actual: "any app"},
+	}, differences)
+}
+
+func (suite *ProtectionDiffSuite) TestStaleChecksWithoutDerivedChecks() {
+	existing := suite.createCompliantProtection()
+	existing.RequiredStatusChecks = &github.RequiredStatusChecks{Strict: true, Checks: suite.createActionsChecks("old-job")}
+	request := suite.createRequest()
+	request.RequiredStatusChecks = nil
+	differences := diffBranchProtection(existing, request, false, []string{"old-job"})
+	suite.Equal([]protectionDifference{{attribute: "extra status checks", expected: "not required", actual: "'old-job'"}}, differences)
+}
+
+func (suite *ProtectionDiffSuite) TestCheckBoundToOtherApp() {
+	existing := suite.createCompliantProtection()
+	otherAppId := int64(42)
+	existing.RequiredStatusChecks.Checks[1].AppID = &otherAppId
+	differences := diffBranchProtection(existing, suite.createRequest(), false, nil)
+	suite.Equal([]protectionDifference{{attribute: "app of status check 'build'", expected: "app 15368 (GitHub Actions)", actual: "app 42"}}, differences)
+}
+
+func (suite *ProtectionDiffSuite) TestMissingSections() {
+	existing := suite.createCompliantProtection()
+	existing.RequiredPullRequestReviews = nil
+	existing.RequiredStatusChecks = nil
+	existing.Restrictions = nil
+	differences := diffBranchProtection(existing, suite.createRequest(), false, nil)
+	suite.Equal([]protectionDifference{
+		{attribute: "pull request reviews", expected: "required", actual: "not required"},
+		{attribute: "required status checks", expected: "'build', 'test'", actual: "none"},
+		{attribute: "push restrictions", expected: "teams none, users none, apps none", actual: "none"},
+	}, differences)
+}
+
+func (suite *ProtectionDiffSuite) TestDifferentRestrictions() {
+	existing := suite.createCompliantProtection()
+	teamSlug := "release-team"
+	existing.Restrictions = &github.BranchRestrictions{Teams: []*github.Team{{Slug: &teamSlug}}}
+	differences := diffBranchProtection(existing, suite.createRequest(), false, nil)
+	suite.Equal([]protectionDifference{{attribute: "push restriction teams", expected: "none", actual: "'release-team'"}}, differences)
+}
+
+func (suite *ProtectionDiffSuite) TestRestrictionsAreComparedByLoginAndSlug() {
+	existing := suite.createCompliantProtection()
+	userLogin, teamSlug, teamName, appSlug := "release-bot", "release-team", "Release Team", "release-app"
+	existing.Restrictions = &github.BranchRestrictions{
+		Users: []*github.User{{Login: &userLogin}},
+		Teams: []*github.Team{{Slug: &teamSlug, Name: &teamName}},
+		Apps:  []*github.App{{Slug: &appSlug}},
+	}
+	request := suite.createRequest()
+	request.Restrictions = &github.BranchRestrictionsRequest{Users: []string{userLogin}, Teams: []string{teamSlug}, Apps: []string{appSlug}}
+	suite.Empty(diffBranchProtection(existing, request, false, nil))
+}
+
+func (suite *ProtectionDiffSuite) TestToFinding() {
+	difference := protectionDifference{attribute: "enforce admins", expected: "true", actual: "false"}
+	suite.Equal(Finding{Kind: protectionDriftFinding, Repo: "my-repo", Branch: "main", Attribute: "enforce admins", Expected: "true",
+		Actual: "false", Message: "enforce admins: expected true, actual false"}, difference.toFinding("my-repo", "main"))
+}
@@ -4,18 +4,26 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/google/go-github/v43/github"
+	"github.com/google/go-github/v57/github"
 )
 
+const settingsDriftFinding = "settings-drift"
+const defaultBranchAttribute = "default branch"
+
 type RepoSettingsVerifier struct {
 	githubClient *github.Client
 	repo         string
 	org          string
+	policy       *RepoSettingsPolicy
+	// report collects the findings. It may be nil.
+	report *findingsReport
 }
 
 type RepoProblemHandler interface {
-	handleWrongSettings(template *github.Repository)
+	handleWrongSettings(template *github.Repository, differences []protectionDifference)
+	handleWrongDefaultBranch(actual string, expected string)
 	handleMissingSecurityAlerts()
+	handleDisabledSecurityFeatures(request *github.SecurityAndAnalysis, differences []protectionDifference) error
 }
 
 type FixRepoProblemHandler struct {
@@ -31,13 +39,41 @@ func (handler FixRepoProblemHandler) handleMissingSecurityAlerts() {
 	}
 }
 
-func (handler FixRepoProblemHandler) handleWrongSettings(template *github.Repository) {
+func (handler FixRepoProblemHandler) handleWrongSettings(template *github.Repository, differences []protectionDifference) {
 	_, _, err := handler.githubClient.Repositories.Edit(context.Background(), handler.org, handler.repo, template)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to update repository settings for %v. Cause: %v", handler.repo, err.Error()))
 	}
 }
 
+// handleWrongDefaultBranch renames the default branch. GitHub retargets open pull requests and moves the branch
+// protection to the new name.
+func (handler FixRepoProblemHandler) handleWrongDefaultBranch(actual string, expected string) {
+	_, _, err := handler.githubClient.Repositories.RenameBranch(context.Background(), handler.org, handler.repo, actual, expected)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to rename default branch %v of %v to %v. Cause: %v", actual, handler.repo, expected, err.Error()))
+	}
+}
+
+// handleDisabledSecurityFeatures enables the security and analysis features of the request and private vulnerability
+// reporting if it is one of the differences. It returns an error if GitHub rejects enabling a feature, e.g. because it
+// is not available for the plan of the repository.
+func (handler FixRepoProblemHandler) handleDisabledSecurityFeatures(request *github.SecurityAndAnalysis, differences []protectionDifference) error {
+	if request.SecretScanning != nil || request.SecretScanningPushProtection != nil || request.DependabotSecurityUpdates != nil {
+		_, _, err := handler.githubClient.Repositories.Edit(context.Background(), handler.org, handler.repo, &github.Repository{SecurityAndAnalysis: request})
+		if err != nil {
+			return fmt.Errorf("failed to enable security and analysis features for %v: %w", handler.repo, err)
+		}
+	}
+	if containsDifference(differences, privateVulnerabilityReportingAttribute) {
+		_, err := handler.githubClient.Repositories.EnablePrivateReporting(context.Background(), handler.org, handler.repo)
+		if err != nil {
+			return fmt.Errorf("failed to enable private vulnerability reporting for %v: %w", handler.repo, err)
+		}
+	}
+	return nil
+}
+
 type LogRepoProblemHandler struct {
 	repo string
 }
@@ -46,46 +82,68 @@ func (handler LogRepoProblemHandler) handleMissingSecurityAlerts() {
 	fmt.Printf("The repository %v does not enable Dependabot alerts.\n", handler.repo)
 }
 
-func (handler LogRepoProblemHandler) handleWrongSettings(template *github.Repository) {
+func (handler LogRepoProblemHandler) handleDisabledSecurityFeatures(request *github.SecurityAndAnalysis, differences []protectionDifference) error {
+	for _, difference := range differences {
+		fmt.Printf("The repository %v does not enable %v (%v).\n", handler.repo, difference.attribute, difference.actual)
+	}
+	return nil
+}
+
+func (handler LogRepoProblemHandler) handleWrongSettings(template *github.Repository, differences []protectionDifference) {
 	fmt.Printf("The repository %v has outdated repo settings.\n", handler.repo)
+	for _, difference := range differences {
+		fmt.Printf("  - %v\n", difference)
+	}
 }
 
-func (verifier *RepoSettingsVerifier) VerifyRepoSettings(fix bool) {
-	problemHandler := verifier.getProblemHandler(fix)
-	verifier.verifyBaseSetting(problemHandler)
-	verifier.verifyVulnerabilityAlerts(problemHandler)
-	verifier.enableDependabot(fix)
+func (handler LogRepoProblemHandler) handleWrongDefaultBranch(actual string, expected string) {
+	fmt.Printf("The repository %v has the default branch %v instead of %v.\n", handler.repo, actual, expected)
 }
 
-func (verifier *RepoSettingsVerifier) verifyBaseSetting(problemHandler RepoProblemHandler) {
+func (verifier *RepoSettingsVerifier) VerifyRepoSettings(fix bool) {
+	if verifier.policy == nil {
+		defaultPolicy := defaultRepoSettingsPolicy()
+		verifier.policy = &defaultPolicy
+	}
+	problemHandler := verifier.getProblemHandler(fix)
 	repo, _, err := verifier.githubClient.Repositories.Get(context.Background(), verifier.org, verifier.repo)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to get settings for repository %v.\n", repo))
+		panic(fmt.Sprintf("Failed to get settings for repository %v.\n", verifier.repo))
 	}
+	verifier.verifyBaseSetting(repo, problemHandler)
+	verifier.verifyVulnerabilityAlerts(problemHandler)
+	verifier.verifySecurityAndAnalysis(repo, problemHandler)
+}
+
+func (verifier *RepoSettingsVerifier) verifyBaseSetting(repo *github.Repository, problemHandler RepoProblemHandler) {
 	repositoryTemplate := verifier.getRepositoryTemplate()
-	if verifier.checkIfRepoMatchesTemplate(repo, repositoryTemplate) {
-		problemHandler.handleWrongSettings(&repositoryTemplate)
+	differences := diffRepoSettings(repo, verifier.policy)
+	verifier.reportDifferences(differences)
+	if containsDifference(differences, defaultBranchAttribute) {
+		problemHandler.handleWrongDefaultBranch(repo.GetDefaultBranch(), *verifier.policy.DefaultBranch)
+	}
+	if settingsDifferences := removeDifference(differences, defaultBranchAttribute); len(settingsDifferences) > 0 {
+		problemHandler.handleWrongSettings(&repositoryTemplate, settingsDifferences)
 	}
 }
 
-func (verifier *RepoSettingsVerifier) enableDependabot(isFix bool) {
-	/* Unfortunately the GitHub API does not support a GET for automated security fixes as of 2022-03. So we can't check
-	if dependabot is enabled. For that reason, we decided to simply enable it in fix mode and don't validate.
-	That's ok, since it's just a comfort feature. The security relevant feature are the alerts.	 */
-	if isFix {
-		_, err := verifier.githubClient.Repositories.EnableAutomatedSecurityFixes(context.Background(), verifier.org, verifier.repo)
-		if err != nil {
-			panic(fmt.Sprintf("Failed to enable security fixes for repository %v.\n.", verifier.repo))
-		}
+func (verifier *RepoSettingsVerifier) reportDifferences(differences []protectionDifference) {
+	for _, difference := range differences {
+		verifier.report.add(Finding{Kind: settingsDriftFinding, Repo: verifier.repo, Attribute: difference.attribute,
+			Expected: difference.expected, Actual: difference.actual, Message: difference.String()})
 	}
 }
 
 func (verifier *RepoSettingsVerifier) verifyVulnerabilityAlerts(problemHandler RepoProblemHandler) {
+	if !verifier.policy.Security.VulnerabilityAlerts {
+		return
+	}
 	alertsEnabled, _, err := verifier.githubClient.Repositories.GetVulnerabilityAlerts(context.Background(), verifier.org, verifier.repo)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to get securtiy alert status for repository %v.\n.", verifier.repo))
 	}
 	if !alertsEnabled {
+		verifier.reportDifferences([]protectionDifference{{attribute: "Dependabot alerts", expected: enabledSecurityStatus, actual: disabledSecurityStatus}})
 		problemHandler.handleMissingSecurityAlerts()
 	}
 }
@@ -98,13 +156,60 @@ func (verifier *RepoSettingsVerifier) getProblemHandler(fix bool) RepoProblemHan
 	}
 }
 
-func (verifier *RepoSettingsVerifier) checkIfRepoMatchesTemplate(repo *github.Repository, repositoryTemplate github.Repository) bool {
-	return *repo.AllowAutoMerge != *repositoryTemplate.AllowAutoMerge || *repo.DeleteBranchOnMerge != *repositoryTemplate.DeleteBranchOnMerge
+// diffRepoSettings compares the settings of the repository with the settings that the policy defines.
+func diffRepoSettings(repo *github.Repository, policy *RepoSettingsPolicy) []protectionDifference {
+	var differences []protectionDifference
+	differences = diffOptionalBool(differences, "allow auto-merge", policy.AllowAutoMerge, repo.GetAllowAutoMerge())
+	differences = diffOptionalBool(differences, "delete branch on merge", policy.DeleteBranchOnMerge, repo.GetDeleteBranchOnMerge())
+	differences = diffOptionalBool(differences, "allow squash merge", policy.AllowSquashMerge, repo.GetAllowSquashMerge())
+	differences = diffOptionalBool(differences, "allow merge commit", policy.AllowMergeCommit, repo.GetAllowMergeCommit())
+	differences = diffOptionalBool(differences, "allow rebase merge", policy.AllowRebaseMerge, repo.GetAllowRebaseMerge())
+	differences = diffOptionalBool(differences, "allow update branch", policy.AllowUpdateBranch, repo.GetAllowUpdateBranch())
+	differences = diffOptionalString(differences, "squash merge commit title", policy.SquashMergeCommitTitle, repo.GetSquashMergeCommitTitle())
+	differences = diffOptionalString(differences, "squash merge commit message", policy.SquashMergeCommitMessage, repo.GetSquashMergeCommitMessage())
+	differences = diffOptionalBool(differences, "has wiki", policy.HasWiki, repo.GetHasWiki())
+	differences = diffOptionalBool(differences, "has projects", policy.HasProjects, repo.GetHasProjects())
+	differences = diffOptionalBool(differences, "has issues", policy.HasIssues, repo.GetHasIssues())
+	differences = diffOptionalBool(differences, "has discussions", policy.HasDiscussions, repo.GetHasDiscussions())
+	differences = diffOptionalBool(differences, "require web commit sign-off", policy.WebCommitSignoffRequired, repo.GetWebCommitSignoffRequired())
+	return diffOptionalString(differences, defaultBranchAttribute, policy.DefaultBranch, repo.GetDefaultBranch())
 }
 
+// diffOptionalString compares a setting that is only managed if the policy defines it.
+func diffOptionalString(differences []protectionDifference, attribute string, expected *string, actual string) []protectionDifference {
+	if expected != nil && *expected != actual {
+		differences = append(differences, protectionDifference{attribute: attribute, expected: *expected, actual: actual})
+	}
+	return differences
+}
+
+func removeDifference(differences []protectionDifference, attribute string) []protectionDifference {
+	var result []protectionDifference
+	for _, difference := range differences {
+		if difference.attribute != attribute {
+			result = append(result, difference)
+		}
+	}
+	return result
+}
+
+// getRepositoryTemplate returns the edit request for the settings of the policy. The default branch is renamed
+// separately, since editing it only switches to another existing branch.
 func (verifier *RepoSettingsVerifier) getRepositoryTemplate() github.Repository {
-	allowAutoMerge := true
-	deleteBranchOnMerge := true
-	repositoryRequest := github.Repository{AllowAutoMerge: &allowAutoMerge, DeleteBranchOnMerge: &deleteBranchOnMerge}
-	return repositoryRequest
+	policy := verifier.policy
+	return github.Repository{
+		AllowAutoMerge:           policy.AllowAutoMerge,
+		DeleteBranchOnMerge:      policy.DeleteBranchOnMerge,
+		AllowSquashMerge:         policy.AllowSquashMerge,
+		AllowMergeCommit:         policy.AllowMergeCommit,
+		AllowRebaseMerge:         policy.AllowRebaseMerge,
+		AllowUpdateBranch:        policy.AllowUpdateBranch,
+		SquashMergeCommitTitle:   policy.SquashMergeCommitTitle,
+		SquashMergeCommitMessage: policy.SquashMergeCommitMessage,
+		HasWiki:                  policy.HasWiki,
+		HasProjects:              policy.HasProjects,
+		HasIssues:                policy.HasIssues,
+		HasDiscussions:           policy.HasDiscussions,
+		WebCommitSignoffRequired: policy.WebCommitSignoffRequired,
+	}
 }
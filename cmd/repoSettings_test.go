@@ -4,7 +4,7 @@ import (
 	"context"
 	"testing"
 
-	"github.com/google/go-github/v43/github"
+	"github.com/google/go-github/v57/github"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -18,16 +18,18 @@ func TestRepoSettingsSuite(t *testing.T) {
 
 func (suite *RepoSettingsSuite) TestInvalidSettings() {
 	suite.resetRepo()
-	verifier := RepoSettingsVerifier{repo: suite.testRepo, org: suite.testOrg, githubClient: suite.githubClient}
+	verifier := suite.createVerifier()
 	output := suite.CaptureOutput(func() {
 		verifier.VerifyRepoSettings(false)
 	})
-	suite.Equal(output, "The repository testing-release-robot has outdated repo settings.\nThe repository testing-release-robot does not enable Dependabot alerts.\n")
+	suite.Equal(output, "The repository testing-release-robot has outdated repo settings.\n"+
+		"  - allow auto-merge: expected true, actual false\n  - delete branch on merge: expected true, actual false\n"+
+		"The repository testing-release-robot does not enable Dependabot alerts.\n")
 }
 
 func (suite *RepoSettingsSuite) TestFix() {
 	suite.resetRepo()
-	verifier := RepoSettingsVerifier{repo: suite.testRepo, org: suite.testOrg, githubClient: suite.githubClient}
+	verifier := suite.createVerifier()
 	verifier.VerifyRepoSettings(true)
 	repo, _, err := suite.githubClient.Repositories.Get(context.Background(), suite.testOrg, suite.testRepo)
 	suite.NoError(err)
@@ -40,7 +42,7 @@ func (suite *RepoSettingsSuite) TestFix() {
 
 func (suite *RepoSettingsSuite) TestSettingsValidAfterFix() {
 	suite.resetRepo()
-	verifier := RepoSettingsVerifier{repo: suite.testRepo, org: suite.testOrg, githubClient: suite.githubClient}
+	verifier := suite.createVerifier()
 	verifier.VerifyRepoSettings(true)
 	output := suite.CaptureOutput(func() {
 		verifier.VerifyRepoSettings(false)
@@ -48,6 +50,15 @@ func (suite *RepoSettingsSuite) TestSettingsValidAfterFix() {
 	suite.Equal(output, "")
 }
 
+// createVerifier creates a verifier that only requires Dependabot alerts of the security features. The verifier would
+// only warn about the other features if the plan of the test organization does not include them, and
+// TestSettingsValidAfterFix expects no output.
+func (suite *RepoSettingsSuite) createVerifier() RepoSettingsVerifier {
+	policy := defaultRepoSettingsPolicy()
+	policy.Security = SecuritySettingsPolicy{VulnerabilityAlerts: true}
+	return RepoSettingsVerifier{repo: suite.testRepo, org: suite.testOrg, githubClient: suite.githubClient, policy: &policy}
+}
+
 func (suite *RepoSettingsSuite) resetRepo() {
 	falsePointer := false
 	_, _, err := suite.githubClient.Repositories.Edit(context.Background(), suite.testOrg, suite.testRepo, &github.Repository{AllowAutoMerge: &falsePointer, DeleteBranchOnMerge: &falsePointer})
@@ -55,3 +66,44 @@ func (suite *RepoSettingsSuite) resetRepo() {
 	_, err = suite.githubClient.Repositories.DisableVulnerabilityAlerts(context.Background(), suite.testOrg, suite.testRepo)
 	suite.NoError(err)
 }
+
+type RepoSettingsDiffSuite struct {
+	suite.Suite
+}
+
+func TestRepoSettingsDiffSuite(t *testing.T) {
+	suite.Run(t, new(RepoSettingsDiffSuite))
+}
+
+func (suite *RepoSettingsDiffSuite) TestDefaultPolicyOnlyManagesMergeSettings() {
+	policy := defaultRepoSettingsPolicy()
+	trueValue := true
+	repo := &github.Repository{AllowAutoMerge: &trueValue, DeleteBranchOnMerge: &trueValue, HasWiki: &trueValue}
+	suite.Empty(diffRepoSettings(repo, &policy))
+}
+
+func (suite *RepoSettingsDiffSuite) TestDifferences() {
+	falseValue := false
+	trueValue := true
+	title := "PR_TITLE"
+	defaultBranch := "main"
+	policy := RepoSettingsPolicy{AllowMergeCommit: &falseValue, AllowUpdateBranch: &trueValue, HasWiki: &falseValue,
+		SquashMergeCommitTitle: &title, DefaultBranch: &defaultBranch}
+	actualTitle := "COMMIT_OR_PR_TITLE"
+	actualBranch := "master"
+	repo := &github.Repository{AllowMergeCommit: &trueValue, HasWiki: &falseValue, SquashMergeCommitTitle: &actualTitle,
+		DefaultBranch: &actualBranch}
+	suite.Equal([]protectionDifference{
+		{attribute: "allow merge commit", expected: "false", actual: "true"},
+		{attribute: "allow update branch", expected: "true", actual: "false"},
+		{attribute: "squash merge commit title", expected: "PR_TITLE", actual: "COMMIT_OR_PR_TITLE"},
+		{attribute: "default branch", expected: "main", actual: "master"},
+	}, diffRepoSettings(repo, &policy))
+}
+
+func (suite *RepoSettingsDiffSuite) TestTemplateContainsOnlyManagedSettings() {
+	falseValue := false
+	defaultBranch := "main"
+	verifier := RepoSettingsVerifier{policy: &RepoSettingsPolicy{HasProjects: &falseValue, DefaultBranch: &defaultBranch}}
+	suite.Equal(github.Repository{HasProjects: &falseValue}, verifier.getRepositoryTemplate())
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// ReportedChecksVerifier warns about required checks that GitHub never reported for the recent commits of a branch
+// and the open pull requests to it. Such checks block all pull requests since they will never be fulfilled.
+type ReportedChecksVerifier struct {
+	repoName    string
+	client      *github.Client
+	commitCount int
+	// report collects the unreported checks. It may be nil.
+	report *findingsReport
+}
+
+const unreportedCheckFinding = "unreported-check"
+
+func (verifier ReportedChecksVerifier) VerifyRequiredChecksAreReported(branch string, requiredChecks []requiredCheck) {
+	reportedChecks := verifier.getReportedChecks(branch)
+	for _, check := range findUnreportedChecks(requiredChecks, reportedChecks) {
+		finding := verifier.createUnreportedCheckFinding(branch, check)
+		printFindingWarning(finding)
+		verifier.report.add(finding)
+	}
+}
+
+func (verifier ReportedChecksVerifier) createUnreportedCheckFinding(branch string, check requiredCheck) Finding {
+	return Finding{Kind: unreportedCheckFinding, Repo: verifier.repoName, Branch: branch,
+		Message: fmt.Sprintf("The required check '%v' from %v was not reported for the last %d commits of branch %v and the open pull requests. Probably the check name that github-keeper derived differs from the name GitHub reports.",
+			check.context, check.origin, verifier.commitCount, branch)}
+}
+
+func findUnreportedChecks(requiredChecks []requiredCheck, reportedChecks []string) []requiredCheck {
+	var result []requiredCheck
+	for _, check := range requiredChecks {
+		if !containsString(reportedChecks, check.context) {
+			result = append(result, check)
+		}
+	}
+	return result
+}
+
+func (verifier ReportedChecksVerifier) getReportedChecks(branch string) []string {
+	var result []string
+	refs := append(verifier.getRecentCommits(branch), verifier.getOpenPullRequestHeads(branch)...)
+	for _, ref := range refs {
+		for _, check := range append(verifier.getCheckRunNames(ref), verifier.getStatusContexts(ref)...) {
+			if !containsString(result, check) {
+				result = append(result, check)
+			}
+		}
+	}
+	return result
+}
+
+func (verifier ReportedChecksVerifier) getRecentCommits(branch string) []string {
+	commits, _, err := verifier.client.Repositories.ListCommits(context.Background(), "exasol", verifier.repoName, &github.CommitsListOptions{SHA: branch, ListOptions: github.ListOptions{PerPage: verifier.commitCount}})
+	if err != nil {
+		panic(fmt.Sprintf("Failed to list commits of exasol/%v/%v. Cause: %v", verifier.repoName, branch, err.Error()))
+	}
+	var result []string
+	for _, commit := range commits {
+		result = append(result, commit.GetSHA())
+	}
+	return result
+}
+
+// getRecentCheckRunNames returns the names of the check runs of the recent commits of the branch.
+func (verifier ReportedChecksVerifier) getRecentCheckRunNames(branch string) []string {
+	var result []string
+	for _, ref := range verifier.getRecentCommits(branch) {
+		result = append(result, verifier.getCheckRunNames(ref)...)
+	}
+	return result
+}
+
+func (verifier ReportedChecksVerifier) getOpenPullRequestHeads(branch string) []string {
+	pullRequests, _, err := verifier.client.PullRequests.List(context.Background(), "exasol", verifier.repoName, &github.PullRequestListOptions{State: "open", Base: branch, ListOptions: github.ListOptions{PerPage: verifier.commitCount}})
+	if err != nil {
+		panic(fmt.Sprintf("Failed to list pull requests of exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+	}
+	var result []string
+	for _, pullRequest := range pullRequests {
+		result = append(result, pullRequest.GetHead().GetSHA())
+	}
+	return result
+}
+
+func (verifier ReportedChecksVerifier) getCheckRunNames(ref string) []string {
+	var result []string
+	options := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		checkRuns, response, err := verifier.client.Checks.ListCheckRunsForRef(context.Background(), "exasol", verifier.repoName, ref, options)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to list check runs of exasol/%v for %v. Cause: %v", verifier.repoName, ref, err.Error()))
+		}
+		for _, checkRun := range checkRuns.CheckRuns {
+			result = append(result, checkRun.GetName())
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+	return result
+}
+
+func (verifier ReportedChecksVerifier) getStatusContexts(ref string) []string {
+	status, _, err := verifier.client.Repositories.GetCombinedStatus(context.Background(), "exasol", verifier.repoName, ref, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get the commit status of exasol/%v for %v. Cause: %v", verifier.repoName, ref, err.Error()))
+	}
+	var result []string
+	for _, repoStatus := range status.Statuses {
+		result = append(result, repoStatus.GetContext())
+	}
+	return result
+}
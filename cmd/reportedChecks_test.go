@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReportedChecksSuite struct {
+	suite.Suite
+}
+
+func TestReportedChecksSuite(t *testing.T) {
+	suite.Run(t, new(ReportedChecksSuite))
+}
+
+func (suite *ReportedChecksSuite) TestFindUnreportedChecks() {
+	requiredChecks := []requiredCheck{
+		{context: "build", origin: "workflow .github/workflows/ci-build.yml"},
+		{context: "Build with Python 3.1", origin: "workflow .github/workflows/ci-build.yml"},
+		{context: "SonarCloud Code Analysis", origin: "SonarCloud"},
+	}
+	unreported := findUnreportedChecks(requiredChecks, []string{"build", "Build with Python 3.10", "SonarCloud Code Analysis"})
+	suite.Equal([]requiredCheck{{context: "Build with Python 3.1", origin: "workflow .github/workflows/ci-build.yml"}}, unreported)
+}
+
+func (suite *ReportedChecksSuite) TestFindUnreportedChecksWithAllChecksReported() {
+	requiredChecks := []requiredCheck{{context: "build", origin: "workflow .github/workflows/ci-build.yml"}}
+	suite.Empty(findUnreportedChecks(requiredChecks, []string{"build", "other"}))
+}
+
+func (suite *ReportedChecksSuite) TestUnreportedCheckFinding() {
+	verifier := ReportedChecksVerifier{repoName: "my-repo", commitCount: 10}
+	finding := verifier.createUnreportedCheckFinding("main", requiredCheck{context: "build", origin: "workflow .github/workflows/ci-build.yml"})
+	suite.Equal(Finding{Kind: unreportedCheckFinding, Repo: "my-repo", Branch: "main",
+		Message: "The required check 'build' from workflow .github/workflows/ci-build.yml was not reported for the last 10 commits of branch main and the open pull requests. Probably the check name that github-keeper derived differs from the name GitHub reports."}, finding)
+}
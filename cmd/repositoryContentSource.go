@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+const workflowsDirectory = ".github/workflows"
+
+// repositoryContentSource provides the files of a repository, e.g. its workflows, either from GitHub or from a local
+// checkout.
+type repositoryContentSource interface {
+	reusableWorkflowLoader
+	// listWorkflowFiles returns the paths of the workflow files relative to the repository root.
+	listWorkflowFiles() ([]string, error)
+	readFile(path string) (string, error)
+	// getFileUrl returns the location of a file for messages.
+	getFileUrl(path string) string
+}
+
+// githubContentSource reads the files of a branch from GitHub. Local reusable workflows are read from the same
+// branch.
+type githubContentSource struct {
+	client   *github.Client
+	repoName string
+	branch   string
+}
+
+func (source githubContentSource) listWorkflowFiles() ([]string, error) {
+	_, directory, _, err := source.client.Repositories.GetContents(context.Background(), "exasol", source.repoName, workflowsDirectory+"/", &github.RepositoryContentGetOptions{Ref: source.branch})
+	if err != nil {
+		if strings.Contains(err.Error(), "404 Not Found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var result []string
+	for _, fileDesc := range directory {
+		if fileDesc.GetType() != "dir" {
+			result = append(result, fileDesc.GetPath())
+		}
+	}
+	return result, nil
+}
+
+func (source githubContentSource) readFile(path string) (string, error) {
+	workflowFile, _, _, err := source.client.Repositories.GetContents(context.Background(), "exasol", source.repoName, path, &github.RepositoryContentGetOptions{Ref: source.branch})
+	if err != nil {
+		return "", err
+	}
+	return workflowFile.GetContent()
+}
+
+func (source githubContentSource) getFileUrl(path string) string {
+	return fmt.Sprintf("https://github.com/exasol/%s/blob/%s/%s", source.repoName, source.branch, path)
+}
+
+func (source githubContentSource) loadWorkflow(reference *reusableWorkflowReference) (string, error) {
+	if reference.repo != "" {
+		source = githubContentSource{client: source.client, repoName: reference.repo, branch: reference.ref}
+	}
+	return source.readFile(reference.path)
+}
+
+// localContentSource reads the files from a local checkout of a repository.
+type localContentSource struct {
+	repositoryRoot string
+	// getClient creates the GitHub client for loading reusable workflows of other repositories. It is only called if
+	// such a workflow is used.
+	getClient func() *github.Client
+}
+
+// newLocalContentSource creates a content source for the given directory, which is either the root of a repository
+// checkout or its workflows directory.
+func newLocalContentSource(directory string, getClient func() *github.Client) (*localContentSource, error) {
+	directory = filepath.Clean(directory)
+	if _, err := os.Stat(filepath.Join(directory, workflowsDirectory)); err == nil {
+		return &localContentSource{repositoryRoot: directory, getClient: getClient}, nil
+	}
+	if filepath.ToSlash(directory) == workflowsDirectory || strings.HasSuffix(filepath.ToSlash(directory), "/"+workflowsDirectory) {
+		return &localContentSource{repositoryRoot: filepath.Dir(filepath.Dir(directory)), getClient: getClient}, nil
+	}
+	return nil, fmt.Errorf("the directory '%v' neither is a repository with a %v directory nor a %v directory", directory, workflowsDirectory, workflowsDirectory)
+}
+
+func (source localContentSource) listWorkflowFiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(source.repositoryRoot, workflowsDirectory))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var result []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			result = append(result, workflowsDirectory+"/"+entry.Name())
+		}
+	}
+	return result, nil
+}
+
+func (source localContentSource) readFile(path string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(source.repositoryRoot, filepath.FromSlash(path)))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func (source localContentSource) getFileUrl(path string) string {
+	return filepath.Join(source.repositoryRoot, filepath.FromSlash(path))
+}
+
+func (source localContentSource) loadWorkflow(reference *reusableWorkflowReference) (string, error) {
+	if reference.repo == "" {
+		return source.readFile(reference.path)
+	}
+	if source.getClient == nil {
+		return "", fmt.Errorf("reusable workflows of other repositories can't be loaded without GitHub access")
+	}
+	return githubContentSource{client: source.getClient(), repoName: reference.repo, branch: reference.ref}.readFile(reference.path)
+}
+
+// readOptionalContent reads a file of the repository source. It returns false if the file does not exist.
+func readOptionalContent(source repositoryContentSource, path string) (string, bool) {
+	content, err := source.readFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || strings.Contains(err.Error(), "404 Not Found") {
+			return "", false
+		}
+		panic(fmt.Sprintf("Failed to read %v. Cause: %v", source.getFileUrl(path), err.Error()))
+	}
+	return content, true
+}
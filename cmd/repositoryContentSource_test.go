@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LocalContentSourceSuite struct {
+	suite.Suite
+	repositoryRoot string
+}
+
+func TestLocalContentSourceSuite(t *testing.T) {
+	suite.Run(t, new(LocalContentSourceSuite))
+}
+
+func (suite *LocalContentSourceSuite) SetupTest() {
+	suite.repositoryRoot = suite.T().TempDir()
+	suite.NoError(os.MkdirAll(filepath.Join(suite.repositoryRoot, ".github", "workflows"), 0o755))
+	suite.writeWorkflow("ci-build.yml", `
+on: [pull_request]
+jobs:
+  build:
+    name: Build
+    runs-on: ubuntu-latest
+  tests:
+    uses: ./.github/workflows/tests.yml
+`)
+	suite.writeWorkflow("tests.yml", `
+on: workflow_call
+jobs:
+  unit:
+    runs-on: ubuntu-latest
+`)
+	suite.writeWorkflow("release.yml", `
+on:
+  push:
+    branches: [main]
+jobs:
+  release:
+    runs-on: ubuntu-latest
+`)
+}
+
+func (suite *LocalContentSourceSuite) writeWorkflow(name string, content string) {
+	suite.NoError(os.WriteFile(filepath.Join(suite.repositoryRoot, ".github", "workflows", name), []byte(content), 0o600))
+}
+
+func (suite *LocalContentSourceSuite) TestGetRequiredChecksFromRepositoryRoot() {
+	source, err := newLocalContentSource(suite.repositoryRoot, nil)
+	suite.NoError(err)
+	checks, complete, err := getRequiredChecksFromWorkflows(source, "main")
+	suite.True(complete)
+	suite.NoError(err)
+	suite.Equal([]requiredCheck{
+		{context: "Build", origin: "workflow .github/workflows/ci-build.yml", job: "build", appId: githubActionsAppId},
+		{context: "tests / unit", origin: "workflow .github/workflows/ci-build.yml", job: "tests / unit", appId: githubActionsAppId},
+	}, sortRequiredChecks(checks))
+}
+
+func (suite *LocalContentSourceSuite) TestRequiredChecksWithUnresolvableMatrixAreIncomplete() {
+	suite.writeWorkflow("matrix.yml", `
+on: [pull_request]
+jobs:
+  tests:
+    strategy:
+      matrix:
+        path: ${{ fromJSON(needs.prepare.outputs.paths) }}
+    runs-on: ubuntu-latest
+`)
+	source, err := newLocalContentSource(suite.repositoryRoot, nil)
+	suite.NoError(err)
+	_, complete, err := getRequiredChecksFromWorkflows(source, "main")
+	suite.NoError(err)
+	suite.False(complete)
+}
+
+func (suite *LocalContentSourceSuite) TestGetRequiredChecksFromWorkflowsDirectory() {
+	source, err := newLocalContentSource(filepath.Join(suite.repositoryRoot, ".github", "workflows"), nil)
+	suite.NoError(err)
+	suite.Equal(suite.repositoryRoot, source.repositoryRoot)
+}
+
+func (suite *LocalContentSourceSuite) TestOtherDirectoryIsRejected() {
+	_, err := newLocalContentSource(suite.T().TempDir(), nil)
+	suite.ErrorContains(err, "neither is a repository")
+}
+
+func (suite *LocalContentSourceSuite) TestRemoteReusableWorkflowWithoutGithubAccess() {
+	source, err := newLocalContentSource(suite.repositoryRoot, nil)
+	suite.NoError(err)
+	_, err = source.loadWorkflow(&reusableWorkflowReference{repo: "shared", path: ".github/workflows/x.yml", ref: "main"})
+	suite.ErrorContains(err, "can't be loaded without GitHub access")
+}
+
+func sortRequiredChecks(checks []requiredCheck) []requiredCheck {
+	sort.Slice(checks, func(i, j int) bool { return checks[i].context < checks[j].context })
+	return checks
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// repositoryFixture is the base of test suites for verifiers that read the files of a repository. Each test gets an
+// empty repository checkout in a temporary directory.
+type repositoryFixture struct {
+	suite.Suite
+	repositoryRoot string
+}
+
+func (fixture *repositoryFixture) SetupTest() {
+	fixture.repositoryRoot = fixture.T().TempDir()
+}
+
+// writeFile writes a file of the repository. The path is relative to the repository root.
+func (fixture *repositoryFixture) writeFile(path string, content string) {
+	fullPath := filepath.Join(fixture.repositoryRoot, filepath.FromSlash(path))
+	fixture.NoError(os.MkdirAll(filepath.Dir(fullPath), 0o755))
+	fixture.NoError(os.WriteFile(fullPath, []byte(content), 0o600))
+}
+
+func (fixture *repositoryFixture) source() localContentSource {
+	return localContentSource{repositoryRoot: fixture.repositoryRoot}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var requiredChecksCmd = &cobra.Command{
+	Use:   "required-checks <directory>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Print the required checks that github-keeper derives from the workflows of a local repository checkout",
+	Long:  "Parses the workflows of a local repository checkout (or of its .github/workflows directory) and prints the checks that github-keeper would require for pull requests to the given branch. This allows previewing the effect of a workflow change on the branch protection before pushing it.",
+	Run: func(cmd *cobra.Command, args []string) {
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			panic(fmt.Sprintf("Could not read parameter branch: %v", err.Error()))
+		}
+		source, err := newLocalContentSource(args[0], getGithubClient)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to read workflows. Cause: %v", err.Error()))
+		}
+		checks, _, err := getRequiredChecksFromWorkflows(source, branch)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to get required checks from workflows in %v. Cause: %v", args[0], err.Error()))
+		}
+		policy := readPolicyFromFlags(cmd).BranchProtection
+		if policy.SonarCheckName != "" {
+			detector := sonarDetector{source: source, checkName: policy.SonarCheckName}
+			if evidence := detector.findEvidence(); evidence != "" {
+				checks = append(checks, newSonarCheck(policy.SonarCheckName, policy.SonarAppId, evidence))
+			}
+		}
+		printRequiredChecks(branch, checks)
+	},
+}
+
+func printRequiredChecks(branch string, checks []requiredCheck) {
+	if len(checks) == 0 {
+		fmt.Printf("The workflows don't produce required checks for pull requests to %v.\n", branch)
+		return
+	}
+	fmt.Printf("Required checks for pull requests to %v:\n", branch)
+	for _, check := range checks {
+		fmt.Printf("  %v (%v)\n", check.context, check.describeOrigin())
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(requiredChecksCmd)
+	requiredChecksCmd.Flags().String("branch", "main", "Target branch of the pull requests")
+	requiredChecksCmd.Flags().String("policy", getDefaultPolicyFile(), "Use a different policy file location")
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/google/go-github/v57/github"
+)
+
+const missingRequiredFileFinding = "missing-required-file"
+const requiredFileContentFinding = "required-file-content"
+
+const requiredFilesBranch = "github-keeper/required-files"
+
+// RequiredFilesVerifier verifies that a repository contains the files of the policy, e.g. LICENSE and SECURITY.md.
+type RequiredFilesVerifier struct {
+	client   *github.Client
+	repoName string
+	files    []RequiredFile
+	// report collects the findings. It may be nil.
+	report *findingsReport
+}
+
+func (verifier RequiredFilesVerifier) VerifyRequiredFiles(fix bool) {
+	if len(verifier.files) == 0 {
+		return
+	}
+	repo, _, err := verifier.client.Repositories.Get(context.Background(), "exasol", verifier.repoName)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get repository exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+	}
+	source := githubContentSource{client: verifier.client, repoName: verifier.repoName, branch: repo.GetDefaultBranch()}
+	findings := verifier.verifyRequiredFilesOfSource(source)
+	for _, finding := range findings {
+		printFindingWarning(finding)
+	}
+	verifier.report.add(findings...)
+	if fix {
+		verifier.proposeMissingFiles(findings)
+	}
+}
+
+func (verifier RequiredFilesVerifier) verifyRequiredFilesOfSource(source repositoryContentSource) []Finding {
+	var findings []Finding
+	for _, file := range verifier.files {
+		content, found := readOptionalContent(source, file.Path)
+		if !found {
+			if file.MustExist {
+				findings = append(findings, Finding{Kind: missingRequiredFileFinding, Repo: verifier.repoName, File: file.Path,
+					Message: "the required file is missing"})
+			}
+		} else if file.Pattern != "" && !compileRequiredFilePattern(file).MatchString(content) {
+			findings = append(findings, Finding{Kind: requiredFileContentFinding, Repo: verifier.repoName, File: file.Path,
+				Message: fmt.Sprintf("the content does not match the pattern '%v'", file.Pattern)})
+		}
+	}
+	return findings
+}
+
+func compileRequiredFilePattern(file RequiredFile) *regexp.Regexp {
+	pattern, err := regexp.Compile(file.Pattern)
+	if err != nil {
+		panic(fmt.Sprintf("The policy contains the invalid pattern '%v' for file %v. Cause: %v", file.Pattern, file.Path, err.Error()))
+	}
+	return pattern
+}
+
+// proposeMissingFiles opens a pull request with the templates of the missing files. Files with wrong content are not
+// replaced, since their content may be valuable.
+func (verifier RequiredFilesVerifier) proposeMissingFiles(findings []Finding) {
+	var files []proposedFile
+	body := "github-keeper added the following required files:\n\n"
+	for _, finding := range findings {
+		if finding.Kind != missingRequiredFileFinding {
+			continue
+		}
+		requiredFile := verifier.findRequiredFile(finding.File)
+		if requiredFile.Template == "" {
+			fmt.Printf("Can't create %v in exasol/%v since the policy does not define a template for it.\n", requiredFile.Path, verifier.repoName)
+			continue
+		}
+		files = append(files, proposedFile{path: requiredFile.Path, content: renderRequiredFileTemplate(*requiredFile, verifier.repoName)})
+		body += "* " + requiredFile.Path + "\n"
+	}
+	if len(files) > 0 {
+		FileProposer{client: verifier.client, repoName: verifier.repoName}.Propose(fileProposal{files: files,
+			branch: requiredFilesBranch, title: "Add required files", body: body})
+	}
+}
+
+func (verifier RequiredFilesVerifier) findRequiredFile(path string) *RequiredFile {
+	for index := range verifier.files {
+		if verifier.files[index].Path == path {
+			return &verifier.files[index]
+		}
+	}
+	panic(fmt.Sprintf("The policy does not contain the required file %v.", path))
+}
+
+// renderRequiredFileTemplate replaces the placeholders of the template, e.g. {{.Repo}}.
+func renderRequiredFileTemplate(file RequiredFile, repoName string) string {
+	fileTemplate, err := template.New(file.Path).Parse(file.Template)
+	if err != nil {
+		panic(fmt.Sprintf("The policy contains an invalid template for file %v. Cause: %v", file.Path, err.Error()))
+	}
+	var content strings.Builder
+	err = fileTemplate.Execute(&content, struct{ Repo string }{Repo: repoName})
+	if err != nil {
+		panic(fmt.Sprintf("Failed to render the template for file %v. Cause: %v", file.Path, err.Error()))
+	}
+	return content.String()
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RequiredFilesSuite struct {
+	repositoryFixture
+}
+
+func TestRequiredFilesSuite(t *testing.T) {
+	suite.Run(t, new(RequiredFilesSuite))
+}
+
+func (suite *RequiredFilesSuite) verify(files ...RequiredFile) []Finding {
+	verifier := RequiredFilesVerifier{repoName: "my-repo", files: files}
+	return verifier.verifyRequiredFilesOfSource(suite.source())
+}
+
+func (suite *RequiredFilesSuite) TestDefaultFiles() {
+	suite.writeFile("LICENSE", "MIT License")
+	suite.writeFile("README.md", "# My Repo")
+	suite.writeFile("doc/changes/changelog.md", "# Changes")
+	suite.Equal([]Finding{
+		{Kind: missingRequiredFileFinding, Repo: "my-repo", File: "SECURITY.md", Message: "the required file is missing"},
+		{Kind: missingRequiredFileFinding, Repo: "my-repo", File: ".gitignore", Message: "the required file is missing"},
+	}, suite.verify(defaultRequiredFiles()...))
+}
+
+func (suite *RequiredFilesSuite) TestOptionalFileIsOnlyVerifiedIfItExists() {
+	file := RequiredFile{Path: "CONTRIBUTING.md", Pattern: "Exasol"}
+	suite.Empty(suite.verify(file))
+	suite.writeFile("CONTRIBUTING.md", "Contributions are welcome")
+	suite.Equal([]Finding{{Kind: requiredFileContentFinding, Repo: "my-repo", File: "CONTRIBUTING.md",
+		Message: "the content does not match the pattern 'Exasol'"}}, suite.verify(file))
+}
+
+func (suite *RequiredFilesSuite) TestContentMatchesPattern() {
+	suite.writeFile("LICENSE", "MIT License\n\nCopyright (c) 2022 Exasol AG")
+	suite.Empty(suite.verify(RequiredFile{Path: "LICENSE", MustExist: true, Pattern: `Copyright \(c\) \d{4} Exasol`}))
+}
+
+func (suite *RequiredFilesSuite) TestInvalidPatternPanics() {
+	suite.writeFile("LICENSE", "MIT License")
+	suite.Panics(func() { suite.verify(RequiredFile{Path: "LICENSE", Pattern: "("}) })
+}
+
+func (suite *RequiredFilesSuite) TestRenderTemplate() {
+	file := RequiredFile{Path: "README.md", Template: "# {{.Repo}}\n"}
+	suite.Equal("# my-repo\n", renderRequiredFileTemplate(file, "my-repo"))
+}
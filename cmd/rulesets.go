@@ -0,0 +1,555 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// RulesetVerifier verifies the branch protection using repository rulesets instead of classic branch protection.
+type RulesetVerifier struct {
+	repoName              string
+	client                *github.Client
+	policy                *BranchProtectionPolicy
+	reportedChecksHistory int
+	// report collects the differences to the expected rulesets and the warnings. It may be nil.
+	report *findingsReport
+}
+
+// defaultBranchRulesetRef is the ref condition of rulesets that matches the default branch.
+const defaultBranchRulesetRef = "~DEFAULT_BRANCH"
+
+// branchSpecificCheckFinding is reported for checks that only some branches of a template require. A ruleset requires
+// the same checks on all branches it applies to.
+const branchSpecificCheckFinding = "branch-specific-check"
+
+// unsupportedProtectionSettingFinding is reported for template settings that rulesets can't express.
+const unsupportedProtectionSettingFinding = "unsupported-protection-setting"
+
+// repositoryAdminRoleId is the ID of the built-in repository admin role used in ruleset bypass lists.
+const repositoryAdminRoleId = 5
+
+type RulesetProblemHandler interface {
+	createRuleset(repo string, ruleset *github.Ruleset)
+	updateRuleset(repo string, existing *github.Ruleset, ruleset *github.Ruleset, differences []protectionDifference)
+	removeStaleChecks(repo string, ruleset *github.Ruleset, staleChecks []string)
+}
+
+type LogRulesetProblemHandler struct {
+}
+
+func (handler LogRulesetProblemHandler) createRuleset(repo string, ruleset *github.Ruleset) {
+	fmt.Printf("exasol/%v does not have the ruleset '%v'. Use --fix to create it.\n", repo, ruleset.Name)
+}
+
+func (handler LogRulesetProblemHandler) updateRuleset(repo string, existing *github.Ruleset, ruleset *github.Ruleset, differences []protectionDifference) {
+	fmt.Printf("exasol/%v has a ruleset '%v' that is not compliant to our standards. Use --fix to update.\n", repo, ruleset.Name)
+	for _, difference := range differences {
+		fmt.Printf("  - %v\n", difference)
+	}
+}
+
+func (handler LogRulesetProblemHandler) removeStaleChecks(repo string, ruleset *github.Ruleset, staleChecks []string) {
+	for _, staleCheck := range staleChecks {
+		fmt.Printf("exasol/%v requires the status check '%v' in ruleset '%v' that no workflow produces anymore. Use --fix to remove it or add it to keepChecks of the policy.\n", repo, staleCheck, ruleset.Name)
+	}
+}
+
+type FixRulesetProblemHandler struct {
+	client *github.Client
+}
+
+func (handler FixRulesetProblemHandler) createRuleset(repo string, ruleset *github.Ruleset) {
+	_, _, err := handler.client.Repositories.CreateRuleset(context.Background(), "exasol", repo, ruleset)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create ruleset '%v' for exasol/%v. Cause: %v", ruleset.Name, repo, err.Error()))
+	}
+	fmt.Printf("Sucessfully created ruleset '%v' for exasol/%v.\n", ruleset.Name, repo)
+}
+
+func (handler FixRulesetProblemHandler) updateRuleset(repo string, existing *github.Ruleset, ruleset *github.Ruleset, differences []protectionDifference) {
+	_, _, err := handler.client.Repositories.UpdateRuleset(context.Background(), "exasol", repo, existing.GetID(), ruleset)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to update ruleset '%v' for exasol/%v. Cause: %v", ruleset.Name, repo, err.Error()))
+	}
+	fmt.Printf("Sucessfully updated ruleset '%v' for exasol/%v.\n", ruleset.Name, repo)
+}
+
+func (handler FixRulesetProblemHandler) removeStaleChecks(repo string, ruleset *github.Ruleset, staleChecks []string) {
+	for _, staleCheck := range staleChecks {
+		fmt.Printf("Removing stale required status check '%v' from ruleset '%v' of exasol/%v.\n", staleCheck, ruleset.Name, repo)
+	}
+	removeChecksFromRuleset(ruleset, staleChecks)
+}
+
+func (verifier RulesetVerifier) getProblemHandler(fix bool) RulesetProblemHandler {
+	if fix {
+		return FixRulesetProblemHandler{verifier.client}
+	} else {
+		return LogRulesetProblemHandler{}
+	}
+}
+
+// CheckIfRulesetsAreApplied verifies that there is one ruleset for each protection template of the policy.
+func (verifier RulesetVerifier) CheckIfRulesetsAreApplied(fix bool) {
+	problemHandler := verifier.getProblemHandler(fix)
+	branchProtectionVerifier := verifier.getBranchProtectionVerifier()
+	repo := branchProtectionVerifier.getRepo()
+	existingRulesets := verifier.listRulesets()
+	branchesPerTemplate := groupBranchesByTemplate(branchProtectionVerifier.getBranchesToProtect(repo))
+	for _, templateName := range verifier.getUsedTemplateNames() {
+		template := verifier.policy.Templates[templateName]
+		verifier.reportUnsupportedSettings(templateName, &template)
+		branches := branchesPerTemplate[templateName]
+		requiredChecks, complete := verifier.getRequiredChecksForBranches(branchProtectionVerifier, templateName, branches)
+		expected := createRulesetFromTemplate(verifier.getRulesetName(templateName), verifier.getRefConditions(templateName), &template, requiredChecks)
+		existing := findRulesetByName(existingRulesets, expected.Name)
+		if existing == nil {
+			verifier.reportDifferences(expected.Name, []protectionDifference{{attribute: "ruleset", expected: "present", actual: "missing"}})
+			problemHandler.createRuleset(verifier.repoName, expected)
+		} else {
+			existingWithRules := verifier.getRuleset(existing.GetID())
+			// Without a matching branch the checks are unknown, so the existing checks are kept.
+			managesChecks := template.RequireStatusChecks && len(branches) > 0
+			staleChecks := addExistingChecksToRuleset(existingWithRules, expected, managesChecks, verifier.policy.KeepChecks)
+			if !complete {
+				reportUnverifiableChecks(verifier.repoName, "", staleChecks, verifier.report)
+				staleChecks = nil
+			}
+			differences := diffRuleset(existingWithRules, expected, staleChecks)
+			if len(differences) > 0 {
+				verifier.reportDifferences(expected.Name, differences)
+				problemHandler.removeStaleChecks(verifier.repoName, expected, staleChecks)
+				problemHandler.updateRuleset(verifier.repoName, existingWithRules, expected, differences)
+			}
+		}
+	}
+}
+
+// getRequiredChecksForBranches derives the checks of each branch that uses the template and returns the checks that all
+// of them require and if the checks of all branches are complete. Checks that only some branches require are reported,
+// since a ruleset can't require them per branch.
+func (verifier RulesetVerifier) getRequiredChecksForBranches(branchProtectionVerifier BranchProtectionVerifier, templateName string, branches []protectedBranch) ([]requiredCheck, bool) {
+	var checksPerBranch [][]requiredCheck
+	allComplete := true
+	for _, branch := range branches {
+		requiredChecks, complete := branchProtectionVerifier.getRequiredChecksForBranch(branch)
+		allComplete = allComplete && complete
+		branchProtectionVerifier.verifyChecksAreReported(branch.name, requiredChecks)
+		checksPerBranch = append(checksPerBranch, requiredChecks)
+	}
+	commonChecks := findCommonChecks(checksPerBranch)
+	for index, requiredChecks := range checksPerBranch {
+		for _, check := range requiredChecks {
+			if !containsCheckContext(commonChecks, check.context) {
+				verifier.warn(Finding{Kind: branchSpecificCheckFinding, Repo: verifier.repoName, Branch: branches[index].name,
+					Message: fmt.Sprintf("The check '%v' is only required on some branches that use the template '%v'. The ruleset does not require it.", check.context, templateName)})
+			}
+		}
+	}
+	return commonChecks, allComplete
+}
+
+// findCommonChecks returns the checks of the first list whose context is contained in all other lists.
+func findCommonChecks(checksPerBranch [][]requiredCheck) []requiredCheck {
+	if len(checksPerBranch) == 0 {
+		return nil
+	}
+	var result []requiredCheck
+	for _, check := range checksPerBranch[0] {
+		isCommon := true
+		for _, otherChecks := range checksPerBranch[1:] {
+			if !containsCheckContext(otherChecks, check.context) {
+				isCommon = false
+				break
+			}
+		}
+		if isCommon {
+			result = append(result, check)
+		}
+	}
+	return result
+}
+
+func containsCheckContext(checks []requiredCheck, context string) bool {
+	for _, check := range checks {
+		if check.context == context {
+			return true
+		}
+	}
+	return false
+}
+
+func groupBranchesByTemplate(branches []protectedBranch) map[string][]protectedBranch {
+	result := make(map[string][]protectedBranch)
+	for _, branch := range branches {
+		result[branch.templateName] = append(result[branch.templateName], branch)
+	}
+	return result
+}
+
+// warn prints the finding as warning and adds it to the report.
+func (verifier RulesetVerifier) warn(finding Finding) {
+	printFindingWarning(finding)
+	verifier.report.add(finding)
+}
+
+func (verifier RulesetVerifier) reportDifferences(rulesetName string, differences []protectionDifference) {
+	for _, difference := range differences {
+		finding := difference.toFinding(verifier.repoName, "")
+		finding.Ruleset = rulesetName
+		verifier.report.add(finding)
+	}
+}
+
+// reportUnsupportedSettings reports the settings of the template that rulesets can't express and are therefore not applied.
+func (verifier RulesetVerifier) reportUnsupportedSettings(templateName string, template *ProtectionTemplate) {
+	if !template.BypassPullRequestAllowances.isEmpty() {
+		verifier.warn(Finding{Kind: unsupportedProtectionSettingFinding, Repo: verifier.repoName, Attribute: "bypassPullRequestAllowances",
+			Message: fmt.Sprintf("The template '%v' defines bypassPullRequestAllowances that rulesets don't support. They are not applied.", templateName)})
+	}
+	if !template.DismissalRestrictions.isEmpty() {
+		verifier.warn(Finding{Kind: unsupportedProtectionSettingFinding, Repo: verifier.repoName, Attribute: "dismissalRestrictions",
+			Message: fmt.Sprintf("The template '%v' defines dismissalRestrictions that rulesets don't support. They are not applied.", templateName)})
+	}
+}
+
+func (verifier RulesetVerifier) getBranchProtectionVerifier() BranchProtectionVerifier {
+	return BranchProtectionVerifier{repoName: verifier.repoName, client: verifier.client, policy: verifier.policy, reportedChecksHistory: verifier.reportedChecksHistory, report: verifier.report}
+}
+
+func (verifier RulesetVerifier) getRulesetName(templateName string) string {
+	return verifier.policy.RulesetName + "-" + templateName
+}
+
+// getUsedTemplateNames returns the names of all templates that are referenced by a branch pattern in the order of first use.
+func (verifier RulesetVerifier) getUsedTemplateNames() []string {
+	var result []string
+	for _, rule := range verifier.policy.Branches {
+		if !containsString(result, rule.Template) {
+			result = append(result, rule.Template)
+		}
+	}
+	return result
+}
+
+// getRefConditions converts the branch patterns that use the given template to ruleset ref conditions.
+func (verifier RulesetVerifier) getRefConditions(templateName string) []string {
+	var result []string
+	for _, rule := range verifier.policy.Branches {
+		if rule.Template == templateName {
+			result = append(result, convertBranchPatternToRulesetRef(rule.Pattern))
+		}
+	}
+	return result
+}
+
+func convertBranchPatternToRulesetRef(pattern string) string {
+	if pattern == defaultBranchPattern {
+		return defaultBranchRulesetRef
+	}
+	return "refs/heads/" + pattern
+}
+
+func (verifier RulesetVerifier) listRulesets() []*github.Ruleset {
+	rulesets, response, err := verifier.client.Repositories.GetAllRulesets(context.Background(), "exasol", verifier.repoName, false)
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			return []*github.Ruleset{}
+		}
+		panic(fmt.Sprintf("Failed to list rulesets of exasol/%v. Cause: %v", verifier.repoName, err.Error()))
+	}
+	return rulesets
+}
+
+func (verifier RulesetVerifier) getRuleset(id int64) *github.Ruleset {
+	ruleset, _, err := verifier.client.Repositories.GetRuleset(context.Background(), "exasol", verifier.repoName, id, false)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to get ruleset %v of exasol/%v. Cause: %v", id, verifier.repoName, err.Error()))
+	}
+	return ruleset
+}
+
+func findRulesetByName(rulesets []*github.Ruleset, name string) *github.Ruleset {
+	for _, ruleset := range rulesets {
+		if ruleset.Name == name {
+			return ruleset
+		}
+	}
+	return nil
+}
+
+func createRulesetFromTemplate(name string, refs []string, template *ProtectionTemplate, requiredChecks []requiredCheck) *github.Ruleset {
+	var rules []*github.RepositoryRule
+	if !template.AllowDeletions {
+		rules = append(rules, github.NewDeletionRule())
+	}
+	if !template.AllowForcePushes {
+		rules = append(rules, github.NewNonFastForwardRule())
+	}
+	if template.RequireLinearHistory {
+		rules = append(rules, github.NewRequiredLinearHistoryRule())
+	}
+	if template.BlockCreations {
+		rules = append(rules, github.NewCreationRule())
+	}
+	if template.LockBranch {
+		rules = append(rules, github.NewUpdateRule(&github.UpdateAllowsFetchAndMergeRuleParameters{}))
+	}
+	if template.RequireSignedCommits {
+		rules = append(rules, github.NewRequiredSignaturesRule())
+	}
+	rules = append(rules, github.NewPullRequestRule(&github.PullRequestRuleParameters{
+		DismissStaleReviewsOnPush:      template.DismissStaleReviews,
+		RequireCodeOwnerReview:         template.RequireCodeOwnerReviews,
+		RequiredApprovingReviewCount:   template.RequiredApprovingReviewCount,
+		RequiredReviewThreadResolution: template.RequireConversationResolution,
+	}))
+	if len(requiredChecks) > 0 {
+		rules = append(rules, github.NewRequiredStatusChecksRule(createRequiredStatusChecksRuleParameters(requiredChecks, true)))
+	}
+	return createRuleset(name, refs, rules, template.EnforceAdmins)
+}
+
+func createRequiredStatusChecksRuleParameters(requiredChecks []requiredCheck, strict bool) *github.RequiredStatusChecksRuleParameters {
+	checks := make([]github.RuleRequiredStatusChecks, 0, len(requiredChecks))
+	for _, statusCheck := range toRequiredStatusChecks(requiredChecks) {
+		checks = append(checks, github.RuleRequiredStatusChecks{Context: statusCheck.Context, IntegrationID: statusCheck.AppID})
+	}
+	return &github.RequiredStatusChecksRuleParameters{RequiredStatusChecks: checks, StrictRequiredStatusChecksPolicy: strict}
+}
+
+func createRuleset(name string, refs []string, rules []*github.RepositoryRule, enforceAdmins bool) *github.Ruleset {
+	target := "branch"
+	bypassActors := []*github.BypassActor{}
+	if !enforceAdmins {
+		actorId := int64(repositoryAdminRoleId)
+		actorType := "RepositoryRole"
+		bypassMode := "always"
+		bypassActors = append(bypassActors, &github.BypassActor{ActorID: &actorId, ActorType: &actorType, BypassMode: &bypassMode})
+	}
+	return &github.Ruleset{
+		Name:         name,
+		Target:       &target,
+		Enforcement:  "active",
+		BypassActors: bypassActors,
+		Conditions: &github.RulesetConditions{
+			RefName: &github.RulesetRefConditionParameters{Include: refs, Exclude: []string{}},
+		},
+		Rules: rules,
+	}
+}
+
+// addExistingChecksToRuleset adds the required checks of the existing ruleset to the expected ruleset and returns the
+// stale checks that neither a workflow produces nor the keep list matches. The problem handler removes them. If the
+// template does not manage status checks, the existing rule is kept.
+func addExistingChecksToRuleset(existing *github.Ruleset, expected *github.Ruleset, requireStatusChecks bool, keepChecks []string) []string {
+	existingRule := findRuleByType(existing.Rules, "required_status_checks")
+	if existingRule == nil {
+		return nil
+	}
+	if !requireStatusChecks {
+		expected.Rules = append(expected.Rules, existingRule)
+		return nil
+	}
+	expectedRule := findRuleByType(expected.Rules, "required_status_checks")
+	var expectedParameters *github.RequiredStatusChecksRuleParameters
+	if expectedRule == nil {
+		expectedParameters = createRequiredStatusChecksRuleParameters(nil, true)
+	} else {
+		parameters := readRequiredStatusChecksRuleParameters(expectedRule)
+		expectedParameters = &parameters
+	}
+	var staleChecks []string
+	for _, check := range readRequiredStatusChecksRuleParameters(existingRule).RequiredStatusChecks {
+		if containsString(getRuleCheckContexts(expectedParameters.RequiredStatusChecks), check.Context) {
+			continue
+		}
+		expectedParameters.RequiredStatusChecks = append(expectedParameters.RequiredStatusChecks, check)
+		if !matchesAnyPattern(keepChecks, check.Context) {
+			staleChecks = append(staleChecks, check.Context)
+		}
+	}
+	if len(expectedParameters.RequiredStatusChecks) > 0 {
+		replaceRule(expected, github.NewRequiredStatusChecksRule(expectedParameters))
+	}
+	return staleChecks
+}
+
+// removeChecksFromRuleset removes the given checks from the required status checks rule of the ruleset. Without remaining
+// checks the rule is removed.
+func removeChecksFromRuleset(ruleset *github.Ruleset, checks []string) {
+	rule := findRuleByType(ruleset.Rules, "required_status_checks")
+	if rule == nil {
+		return
+	}
+	parameters := readRequiredStatusChecksRuleParameters(rule)
+	var remainingChecks []github.RuleRequiredStatusChecks
+	for _, check := range parameters.RequiredStatusChecks {
+		if !containsString(checks, check.Context) {
+			remainingChecks = append(remainingChecks, check)
+		}
+	}
+	if len(remainingChecks) == 0 {
+		removeRule(ruleset, rule.Type)
+	} else {
+		parameters.RequiredStatusChecks = remainingChecks
+		replaceRule(ruleset, github.NewRequiredStatusChecksRule(&parameters))
+	}
+}
+
+func removeRule(ruleset *github.Ruleset, ruleType string) {
+	var remainingRules []*github.RepositoryRule
+	for _, rule := range ruleset.Rules {
+		if rule.Type != ruleType {
+			remainingRules = append(remainingRules, rule)
+		}
+	}
+	ruleset.Rules = remainingRules
+}
+
+func replaceRule(ruleset *github.Ruleset, newRule *github.RepositoryRule) {
+	for index, rule := range ruleset.Rules {
+		if rule.Type == newRule.Type {
+			ruleset.Rules[index] = newRule
+			return
+		}
+	}
+	ruleset.Rules = append(ruleset.Rules, newRule)
+}
+
+// checkIfRulesetMatches checks if the existing ruleset enforces at least the rules of the expected ruleset and does not
+// require other status checks.
+func checkIfRulesetMatches(existing *github.Ruleset, expected *github.Ruleset) bool {
+	return len(diffRuleset(existing, expected, nil)) == 0
+}
+
+// diffRuleset compares the existing ruleset with the expected ruleset attribute by attribute. The stale checks are
+// required by the existing ruleset but not by the expected ruleset.
+func diffRuleset(existing *github.Ruleset, expected *github.Ruleset, staleChecks []string) []protectionDifference {
+	var differences []protectionDifference
+	if existing.Enforcement != expected.Enforcement {
+		differences = append(differences, protectionDifference{attribute: "enforcement", expected: expected.Enforcement, actual: existing.Enforcement})
+	}
+	if existing.GetTarget() != expected.GetTarget() {
+		differences = append(differences, protectionDifference{attribute: "target", expected: expected.GetTarget(), actual: existing.GetTarget()})
+	}
+	var existingRefs []string
+	if existing.Conditions != nil && existing.Conditions.RefName != nil {
+		existingRefs = existing.Conditions.RefName.Include
+	}
+	differences = diffList(differences, "branches", expected.Conditions.RefName.Include, existingRefs)
+	differences = diffList(differences, "bypass actors", getBypassActorKeys(expected.BypassActors), getBypassActorKeys(existing.BypassActors))
+	if findRuleByType(expected.Rules, "required_status_checks") == nil && findRuleByType(existing.Rules, "required_status_checks") != nil {
+		differences = append(differences, protectionDifference{attribute: "required status checks", expected: "none",
+			actual: formatList(getRuleCheckContexts(readRequiredStatusChecksRuleParameters(findRuleByType(existing.Rules, "required_status_checks")).RequiredStatusChecks))})
+	}
+	for _, expectedRule := range expected.Rules {
+		existingRule := findRuleByType(existing.Rules, expectedRule.Type)
+		if existingRule == nil {
+			differences = append(differences, protectionDifference{attribute: fmt.Sprintf("rule '%v'", expectedRule.Type), expected: "enabled", actual: "disabled"})
+		} else {
+			differences = append(differences, diffRuleParameters(existingRule, expectedRule)...)
+		}
+	}
+	if len(staleChecks) > 0 {
+		differences = append(differences, protectionDifference{attribute: "extra status checks", expected: "not required", actual: formatList(staleChecks)})
+	}
+	return differences
+}
+
+func diffRuleParameters(existing *github.RepositoryRule, expected *github.RepositoryRule) []protectionDifference {
+	var differences []protectionDifference
+	switch expected.Type {
+	case "pull_request":
+		existingParameters := readPullRequestRuleParameters(existing)
+		expectedParameters := readPullRequestRuleParameters(expected)
+		if existingParameters.RequiredApprovingReviewCount < expectedParameters.RequiredApprovingReviewCount {
+			differences = append(differences, protectionDifference{attribute: "required approving review count",
+				expected: fmt.Sprintf("at least %d", expectedParameters.RequiredApprovingReviewCount), actual: fmt.Sprint(existingParameters.RequiredApprovingReviewCount)})
+		}
+		differences = diffBool(differences, "dismiss stale reviews", expectedParameters.DismissStaleReviewsOnPush, existingParameters.DismissStaleReviewsOnPush)
+		differences = diffBool(differences, "require code owner reviews", expectedParameters.RequireCodeOwnerReview, existingParameters.RequireCodeOwnerReview)
+		differences = diffBool(differences, "require conversation resolution", expectedParameters.RequiredReviewThreadResolution, existingParameters.RequiredReviewThreadResolution)
+	case "required_status_checks":
+		existingParameters := readRequiredStatusChecksRuleParameters(existing)
+		expectedParameters := readRequiredStatusChecksRuleParameters(expected)
+		differences = diffBool(differences, "require branches to be up to date", expectedParameters.StrictRequiredStatusChecksPolicy, existingParameters.StrictRequiredStatusChecksPolicy)
+		differences = diffList(differences, "required status checks", getRuleCheckContexts(expectedParameters.RequiredStatusChecks), getRuleCheckContexts(existingParameters.RequiredStatusChecks))
+		differences = append(differences, diffRuleCheckAppBindings(existingParameters.RequiredStatusChecks, expectedParameters.RequiredStatusChecks)...)
+	}
+	return differences
+}
+
+// getBypassActorKeys describes each bypass actor by its type, ID and bypass mode, e.g. "RepositoryRole:5:always".
+func getBypassActorKeys(actors []*github.BypassActor) []string {
+	result := make([]string, 0, len(actors))
+	for _, actor := range actors {
+		result = append(result, fmt.Sprintf("%v:%v:%v", actor.GetActorType(), actor.GetActorID(), actor.GetBypassMode()))
+	}
+	return result
+}
+
+func findRuleByType(rules []*github.RepositoryRule, ruleType string) *github.RepositoryRule {
+	for _, rule := range rules {
+		if rule.Type == ruleType {
+			return rule
+		}
+	}
+	return nil
+}
+
+func readPullRequestRuleParameters(rule *github.RepositoryRule) github.PullRequestRuleParameters {
+	var parameters github.PullRequestRuleParameters
+	readRuleParameters(rule, &parameters)
+	return parameters
+}
+
+func readRequiredStatusChecksRuleParameters(rule *github.RepositoryRule) github.RequiredStatusChecksRuleParameters {
+	var parameters github.RequiredStatusChecksRuleParameters
+	readRuleParameters(rule, &parameters)
+	return parameters
+}
+
+func readRuleParameters(rule *github.RepositoryRule, parameters interface{}) {
+	if rule.Parameters == nil {
+		return
+	}
+	err := json.Unmarshal(*rule.Parameters, parameters)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read parameters of ruleset rule '%v'. Cause: %v", rule.Type, err.Error()))
+	}
+}
+
+// diffRuleCheckAppBindings returns a difference for each existing check that is not bound to the app of the expected check.
+func diffRuleCheckAppBindings(existing []github.RuleRequiredStatusChecks, expected []github.RuleRequiredStatusChecks) []protectionDifference {
+	var differences []protectionDifference
+	for _, expectedCheck := range expected {
+		for _, existingCheck := range existing {
+			if existingCheck.Context == expectedCheck.Context && !isSameAppBinding(expectedCheck.IntegrationID, existingCheck.IntegrationID) {
+				differences = append(differences, protectionDifference{attribute: fmt.Sprintf("app of status check '%v'", expectedCheck.Context),
+					expected: describeAppBinding(expectedCheck.IntegrationID), actual: describeAppBinding(existingCheck.IntegrationID)})
+			}
+		}
+	}
+	return differences
+}
+
+func getRuleCheckContexts(checks []github.RuleRequiredStatusChecks) []string {
+	result := make([]string, 0, len(checks))
+	for _, check := range checks {
+		result = append(result, check.Context)
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, existingValue := range values {
+		if existingValue == value {
+			return true
+		}
+	}
+	return false
+}
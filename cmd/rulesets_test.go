@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/suite"
+)
+
+type RulesetsSuite struct {
+	suite.Suite
+}
+
+func TestRulesetsSuite(t *testing.T) {
+	suite.Run(t, new(RulesetsSuite))
+}
+
+func (suite *RulesetsSuite) getDefaultTemplate() *ProtectionTemplate {
+	template := defaultBranchProtectionPolicy().Templates["default"]
+	return &template
+}
+
+func (suite *RulesetsSuite) TestCreateRulesetFromTemplate() {
+	ruleset := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build"))
+	suite.Equal("active", ruleset.Enforcement)
+	suite.Equal([]string{defaultBranchRulesetRef}, ruleset.Conditions.RefName.Include)
+	suite.Empty(ruleset.BypassActors)
+	suite.Equal([]string{"deletion", "non_fast_forward", "pull_request", "required_status_checks"}, suite.getRuleTypes(ruleset))
+	suite.Equal([]string{"build"}, getRuleCheckContexts(readRequiredStatusChecksRuleParameters(findRuleByType(ruleset.Rules, "required_status_checks")).RequiredStatusChecks))
+}
+
+func (suite *RulesetsSuite) TestCreateRulesetFromTemplateWithoutAdminEnforcement() {
+	template := suite.getDefaultTemplate()
+	template.EnforceAdmins = false
+	ruleset := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, template, nil)
+	suite.Len(ruleset.BypassActors, 1)
+	suite.Equal("RepositoryRole", ruleset.BypassActors[0].GetActorType())
+	suite.Nil(findRuleByType(ruleset.Rules, "required_status_checks"))
+}
+
+func (suite *RulesetsSuite) TestCreateRulesetFromTemplateWithAdditionalRules() {
+	template := suite.getDefaultTemplate()
+	template.AllowDeletions = true
+	template.RequireLinearHistory = true
+	template.BlockCreations = true
+	template.LockBranch = true
+	template.RequireSignedCommits = true
+	template.RequireConversationResolution = true
+	ruleset := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, template, nil)
+	suite.Equal([]string{"non_fast_forward", "required_linear_history", "creation", "update", "required_signatures", "pull_request"}, suite.getRuleTypes(ruleset))
+	suite.True(readPullRequestRuleParameters(findRuleByType(ruleset.Rules, "pull_request")).RequiredReviewThreadResolution)
+}
+
+func (suite *RulesetsSuite) TestRulesetWithoutConversationResolutionDoesNotMatch() {
+	template := suite.getDefaultTemplate()
+	template.RequireConversationResolution = true
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, template, nil)
+	existing := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), nil)
+	suite.False(checkIfRulesetMatches(existing, expected))
+}
+
+func (suite *RulesetsSuite) TestRulesetWithCheckBoundToOtherAppDoesNotMatch() {
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build"))
+	existing := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), []requiredCheck{{context: "build"}})
+	suite.False(checkIfRulesetMatches(existing, expected))
+}
+
+func (suite *RulesetsSuite) TestRulesetMatchesItself() {
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build"))
+	existing := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build"))
+	suite.Assert().True(checkIfRulesetMatches(existing, expected))
+}
+
+func (suite *RulesetsSuite) TestRulesetWithStaleCheckDoesNotMatch() {
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build"))
+	existing := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build", "other"))
+	staleChecks := addExistingChecksToRuleset(existing, expected, true, []string{})
+	suite.Equal([]string{"other"}, staleChecks)
+	removeChecksFromRuleset(expected, staleChecks)
+	suite.Assert().False(checkIfRulesetMatches(existing, expected))
+}
+
+func (suite *RulesetsSuite) TestAddExistingChecksToRulesetKeepsStaleChecksUntilRemoved() {
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build"))
+	existing := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build", "other"))
+	addExistingChecksToRuleset(existing, expected, true, []string{})
+	suite.Equal([]string{"build", "other"}, getRuleCheckContexts(readRequiredStatusChecksRuleParameters(findRuleByType(expected.Rules, "required_status_checks")).RequiredStatusChecks))
+}
+
+func (suite *RulesetsSuite) TestRemoveAllChecksFromRuleset() {
+	ruleset := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("stale"))
+	removeChecksFromRuleset(ruleset, []string{"stale"})
+	suite.Nil(findRuleByType(ruleset.Rules, "required_status_checks"))
+}
+
+func (suite *RulesetsSuite) TestRulesetWithKeptCheckMatches() {
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build"))
+	existing := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build", "external/check"))
+	staleChecks := addExistingChecksToRuleset(existing, expected, true, []string{"external/*"})
+	suite.Empty(staleChecks)
+	suite.Assert().True(checkIfRulesetMatches(existing, expected))
+}
+
+func (suite *RulesetsSuite) TestExistingChecksAreKeptIfTemplateDoesNotRequireChecks() {
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), nil)
+	existing := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("manual"))
+	staleChecks := addExistingChecksToRuleset(existing, expected, false, []string{})
+	suite.Empty(staleChecks)
+	suite.Assert().True(checkIfRulesetMatches(existing, expected))
+}
+
+func (suite *RulesetsSuite) TestRulesetWithMissingCheckDoesNotMatch() {
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("build"))
+	existing := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), workflowChecks("other"))
+	suite.Assert().False(checkIfRulesetMatches(existing, expected))
+}
+
+func (suite *RulesetsSuite) TestRulesetWithFewerReviewsDoesNotMatch() {
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), nil)
+	template := suite.getDefaultTemplate()
+	template.RequiredApprovingReviewCount = 0
+	existing := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, template, nil)
+	suite.Assert().False(checkIfRulesetMatches(existing, expected))
+}
+
+func (suite *RulesetsSuite) TestRulesetWithOtherBranchesDoesNotMatch() {
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, suite.getDefaultTemplate(), nil)
+	existing := createRulesetFromTemplate("github-keeper-default", []string{"refs/heads/main"}, suite.getDefaultTemplate(), nil)
+	suite.Assert().False(checkIfRulesetMatches(existing, expected))
+}
+
+func (suite *RulesetsSuite) TestRulesetWithOtherBypassActorDoesNotMatch() {
+	template := suite.getDefaultTemplate()
+	template.EnforceAdmins = false
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, template, nil)
+	existing := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, template, nil)
+	otherActorId := int64(4711)
+	otherActorType := "Team"
+	existing.BypassActors[0].ActorID = &otherActorId
+	existing.BypassActors[0].ActorType = &otherActorType
+	suite.Assert().False(checkIfRulesetMatches(existing, expected))
+}
+
+func (suite *RulesetsSuite) TestDiffRuleset() {
+	template := suite.getDefaultTemplate()
+	template.RequireConversationResolution = true
+	expected := createRulesetFromTemplate("github-keeper-default", []string{defaultBranchRulesetRef}, template, workflowChecks("build"))
+	existing := createRulesetFromTemplate("github-keeper-default", []string{"refs/heads/main"}, suite.getDefaultTemplate(), workflowChecks("build", "old"))
+	differences := diffRuleset(existing, expected, []string{"old"})
+	suite.Equal([]protectionDifference{
+		{attribute: "branches", expected: "'~DEFAULT_BRANCH'", actual: "'refs/heads/main'"},
+		{attribute: "require conversation resolution", expected: "true", actual: "false"},
+		{attribute: "required status checks", expected: "'build'", actual: "'build', 'old'"},
+		{attribute: "extra status checks", expected: "not required", actual: "'old'"},
+	}, differences)
+}
+
+func (suite *RulesetsSuite) TestDifferencesAreReported() {
+	report := findingsReport{}
+	verifier := RulesetVerifier{repoName: "my-repo", report: &report}
+	verifier.reportDifferences("github-keeper-default", []protectionDifference{{attribute: "enforcement", expected: "active", actual: "disabled"}})
+	suite.Equal([]Finding{{Kind: protectionDriftFinding, Repo: "my-repo", Ruleset: "github-keeper-default", Attribute: "enforcement", Expected: "active",
+		Actual: "disabled", Message: "enforcement: expected active, actual disabled"}}, report.findings)
+}
+
+func (suite *RulesetsSuite) TestFindCommonChecks() {
+	commonChecks := findCommonChecks([][]requiredCheck{workflowChecks("build", "release-only"), workflowChecks("build", "other")})
+	suite.Equal([]string{"build"}, getCheckContexts(commonChecks))
+}
+
+func (suite *RulesetsSuite) TestFindCommonChecksWithoutBranches() {
+	suite.Empty(findCommonChecks(nil))
+}
+
+func (suite *RulesetsSuite) TestGroupBranchesByTemplate() {
+	branches := []protectedBranch{{name: "main", templateName: "default"}, {name: "release/1", templateName: "release"}, {name: "release/2", templateName: "release"}}
+	grouped := groupBranchesByTemplate(branches)
+	suite.Len(grouped["default"], 1)
+	suite.Len(grouped["release"], 2)
+}
+
+func (suite *RulesetsSuite) TestGetRefConditions() {
+	policy := ReadPolicyFromYaml("../test_resources/policy.yml", true).BranchProtection
+	verifier := RulesetVerifier{policy: &policy}
+	suite.Equal([]string{"default", "release"}, verifier.getUsedTemplateNames())
+	suite.Equal([]string{defaultBranchRulesetRef}, verifier.getRefConditions("default"))
+	suite.Equal([]string{"refs/heads/release/*"}, verifier.getRefConditions("release"))
+}
+
+func (suite *RulesetsSuite) TestGroupBranchesForMigrationUsesManagedRulesetNames() {
+	policy := ReadPolicyFromYaml("../test_resources/policy.yml", true).BranchProtection
+	groups := groupBranchesForMigration(&policy, []string{"main", "release/1.0", "feature", "release/2.0"}, "main")
+	suite.Equal([]*migrationGroup{
+		{rulesetName: policy.RulesetName + "-default", refs: []string{defaultBranchRulesetRef}, branches: []string{"main"}},
+		{rulesetName: policy.RulesetName + "-release", refs: []string{"refs/heads/release/*"}, branches: []string{"release/1.0", "release/2.0"}},
+		{rulesetName: policy.RulesetName + "-migrated-feature", refs: []string{"refs/heads/feature"}, branches: []string{"feature"}},
+	}, groups)
+}
+
+func (suite *RulesetsSuite) TestConvertProtectionToRuleset() {
+	protection := github.Protection{
+		RequiredStatusChecks:           &github.RequiredStatusChecks{Strict: true, Contexts: []string{"build"}},
+		RequiredPullRequestReviews:     &github.PullRequestReviewsEnforcement{DismissStaleReviews: true, RequiredApprovingReviewCount: 2},
+		EnforceAdmins:                  &github.AdminEnforcement{Enabled: true},
+		RequireLinearHistory:           &github.RequireLinearHistory{Enabled: true},
+		AllowForcePushes:               &github.AllowForcePushes{Enabled: false},
+		AllowDeletions:                 &github.AllowDeletions{Enabled: true},
+		RequiredConversationResolution: &github.RequiredConversationResolution{Enabled: true},
+	}
+	ruleset := convertProtectionToRuleset("migrated", []string{"refs/heads/release"}, &protection)
+	suite.Equal([]string{"non_fast_forward", "required_linear_history", "pull_request", "required_status_checks"}, suite.getRuleTypes(ruleset))
+	pullRequestParameters := readPullRequestRuleParameters(findRuleByType(ruleset.Rules, "pull_request"))
+	suite.Equal(2, pullRequestParameters.RequiredApprovingReviewCount)
+	suite.Assert().True(pullRequestParameters.DismissStaleReviewsOnPush)
+	suite.Assert().True(pullRequestParameters.RequiredReviewThreadResolution)
+	statusCheckParameters := readRequiredStatusChecksRuleParameters(findRuleByType(ruleset.Rules, "required_status_checks"))
+	suite.Assert().True(statusCheckParameters.StrictRequiredStatusChecksPolicy)
+	suite.Equal([]string{"build"}, getRuleCheckContexts(statusCheckParameters.RequiredStatusChecks))
+	suite.Empty(ruleset.BypassActors)
+}
+
+func (suite *RulesetsSuite) TestConvertProtectionWithAppBoundChecks() {
+	appId := int64(15368)
+	protection := github.Protection{
+		RequiredStatusChecks: &github.RequiredStatusChecks{Checks: []*github.RequiredStatusCheck{{Context: "build", AppID: &appId}}},
+	}
+	ruleset := convertProtectionToRuleset("migrated", []string{defaultBranchRulesetRef}, &protection)
+	statusCheckParameters := readRequiredStatusChecksRuleParameters(findRuleByType(ruleset.Rules, "required_status_checks"))
+	suite.Equal(appId, *statusCheckParameters.RequiredStatusChecks[0].IntegrationID)
+	suite.Len(ruleset.BypassActors, 1)
+}
+
+func (suite *RulesetsSuite) TestConvertConversationResolutionWithoutReviews() {
+	protection := github.Protection{RequiredConversationResolution: &github.RequiredConversationResolution{Enabled: true}}
+	ruleset := convertProtectionToRuleset("migrated", []string{defaultBranchRulesetRef}, &protection)
+	pullRequestParameters := readPullRequestRuleParameters(findRuleByType(ruleset.Rules, "pull_request"))
+	suite.Assert().True(pullRequestParameters.RequiredReviewThreadResolution)
+	suite.Equal(0, pullRequestParameters.RequiredApprovingReviewCount)
+}
+
+func (suite *RulesetsSuite) TestFindUnsupportedSettings() {
+	login := "release-bot"
+	protection := github.Protection{
+		Restrictions: &github.BranchRestrictions{},
+		RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{
+			BypassPullRequestAllowances: &github.BypassPullRequestAllowances{Users: []*github.User{{Login: &login}}},
+			DismissalRestrictions:       &github.DismissalRestrictions{},
+		},
+	}
+	suite.Equal([]string{"push restrictions", "pull request bypass allowances"}, findUnsupportedSettings(&protection))
+}
+
+func (suite *RulesetsSuite) TestFindUnsupportedSettingsOfConvertibleProtection() {
+	protection := github.Protection{RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{RequiredApprovingReviewCount: 1}}
+	suite.Empty(findUnsupportedSettings(&protection))
+}
+
+func (suite *RulesetsSuite) getRuleTypes(ruleset *github.Ruleset) []string {
+	var result []string
+	for _, rule := range ruleset.Rules {
+		result = append(result, rule.Type)
+	}
+	return result
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+const enabledSecurityStatus = "enabled"
+const disabledSecurityStatus = "disabled"
+const unknownSecurityStatus = "unknown"
+const unavailableSecurityFeatureFinding = "unavailable-security-feature"
+const dependabotSecurityUpdatesAttribute = "Dependabot security updates"
+const secretScanningAttribute = "secret scanning"
+const secretScanningPushProtectionAttribute = "secret scanning push protection"
+const privateVulnerabilityReportingAttribute = "private vulnerability reporting"
+
+// privateVulnerabilityReporting is the response of the GitHub API for the private vulnerability reporting status.
+// go-github does not support reading it yet.
+type privateVulnerabilityReporting struct {
+	Enabled bool `json:"enabled"`
+}
+
+// verifySecurityAndAnalysis compares the security features of the repository with the policy. GitHub only returns
+// the security and analysis settings to admins of the repository. Features with an unknown status are reported as
+// warnings and never enabled, since GitHub rejects enabling features that are not available for the repository.
+func (verifier *RepoSettingsVerifier) verifySecurityAndAnalysis(repo *github.Repository, problemHandler RepoProblemHandler) {
+	policy := verifier.policy.Security
+	privateReportingStatus := enabledSecurityStatus
+	if policy.PrivateVulnerabilityReporting {
+		status, err := verifier.getPrivateVulnerabilityReportingStatus()
+		if err != nil {
+			verifier.warnAboutUnavailableFeature(privateVulnerabilityReportingAttribute, unknownSecurityStatus,
+				fmt.Sprintf("Failed to get the status of %v. Cause: %v", privateVulnerabilityReportingAttribute, err.Error()))
+			policy.PrivateVulnerabilityReporting = false
+		} else {
+			privateReportingStatus = status
+		}
+	}
+	disabled, unavailable := splitUnavailableFeatures(diffSecurityAndAnalysis(repo.GetSecurityAndAnalysis(), policy, privateReportingStatus))
+	for _, difference := range unavailable {
+		verifier.warnAboutUnavailableFeature(difference.attribute, difference.actual, fmt.Sprintf(
+			"GitHub does not report if %v is enabled (%v). Either the feature is not available for the repository or you are not admin of it. github-keeper does not enable it.",
+			difference.attribute, difference.actual))
+	}
+	if len(disabled) > 0 {
+		verifier.reportDifferences(disabled)
+		err := problemHandler.handleDisabledSecurityFeatures(createSecurityAndAnalysisRequest(disabled), disabled)
+		if err != nil {
+			verifier.warnAboutUnavailableFeature("security features", disabledSecurityStatus, fmt.Sprintf("Could not fix the security features: %v", err.Error()))
+		}
+	}
+}
+
+func (verifier *RepoSettingsVerifier) warnAboutUnavailableFeature(attribute string, actual string, message string) {
+	finding := Finding{Kind: unavailableSecurityFeatureFinding, Repo: verifier.repo, Attribute: attribute,
+		Expected: enabledSecurityStatus, Actual: actual, Message: message}
+	printFindingWarning(finding)
+	verifier.report.add(finding)
+}
+
+// getPrivateVulnerabilityReportingStatus returns "enabled" or "disabled".
+func (verifier *RepoSettingsVerifier) getPrivateVulnerabilityReportingStatus() (string, error) {
+	url := fmt.Sprintf("repos/%v/%v/private-vulnerability-reporting", verifier.org, verifier.repo)
+	request, err := verifier.githubClient.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	var status privateVulnerabilityReporting
+	_, err = verifier.githubClient.Do(context.Background(), request, &status)
+	if err != nil {
+		return "", err
+	}
+	if status.Enabled {
+		return enabledSecurityStatus, nil
+	}
+	return disabledSecurityStatus, nil
+}
+
+// diffSecurityAndAnalysis lists the security features that the policy requires but the repository does not enable.
+func diffSecurityAndAnalysis(existing *github.SecurityAndAnalysis, policy SecuritySettingsPolicy, privateReportingStatus string) []protectionDifference {
+	var differences []protectionDifference
+	if policy.DependabotSecurityUpdates {
+		differences = diffSecurityStatus(differences, dependabotSecurityUpdatesAttribute, existing.GetDependabotSecurityUpdates().GetStatus())
+	}
+	if policy.SecretScanning {
+		differences = diffSecurityStatus(differences, secretScanningAttribute, existing.GetSecretScanning().GetStatus())
+	}
+	if policy.SecretScanningPushProtection {
+		differences = diffSecurityStatus(differences, secretScanningPushProtectionAttribute, existing.GetSecretScanningPushProtection().GetStatus())
+	}
+	if policy.PrivateVulnerabilityReporting {
+		differences = diffSecurityStatus(differences, privateVulnerabilityReportingAttribute, privateReportingStatus)
+	}
+	return differences
+}
+
+// diffSecurityStatus compares the status of a security feature. A missing status means that GitHub did not return it,
+// e.g. because the feature is not available for the repository.
+func diffSecurityStatus(differences []protectionDifference, attribute string, actual string) []protectionDifference {
+	if actual == enabledSecurityStatus {
+		return differences
+	}
+	if actual == "" {
+		actual = unknownSecurityStatus
+	}
+	return append(differences, protectionDifference{attribute: attribute, expected: enabledSecurityStatus, actual: actual})
+}
+
+// splitUnavailableFeatures separates the disabled features from the features with any other status. Only disabled
+// features can be enabled.
+func splitUnavailableFeatures(differences []protectionDifference) (disabled []protectionDifference, unavailable []protectionDifference) {
+	for _, difference := range differences {
+		if difference.actual == disabledSecurityStatus {
+			disabled = append(disabled, difference)
+		} else {
+			unavailable = append(unavailable, difference)
+		}
+	}
+	return disabled, unavailable
+}
+
+// createSecurityAndAnalysisRequest returns the edit request that enables the features of the differences.
+func createSecurityAndAnalysisRequest(differences []protectionDifference) *github.SecurityAndAnalysis {
+	enabled := enabledSecurityStatus
+	request := &github.SecurityAndAnalysis{}
+	for _, difference := range differences {
+		switch difference.attribute {
+		case dependabotSecurityUpdatesAttribute:
+			request.DependabotSecurityUpdates = &github.DependabotSecurityUpdates{Status: &enabled}
+		case secretScanningAttribute:
+			request.SecretScanning = &github.SecretScanning{Status: &enabled}
+		case secretScanningPushProtectionAttribute:
+			request.SecretScanningPushProtection = &github.SecretScanningPushProtection{Status: &enabled}
+		}
+	}
+	return request
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/suite"
+)
+
+type SecuritySettingsSuite struct {
+	suite.Suite
+}
+
+func TestSecuritySettingsSuite(t *testing.T) {
+	suite.Run(t, new(SecuritySettingsSuite))
+}
+
+func (suite *SecuritySettingsSuite) createSecurityAndAnalysis(dependabot string, secretScanning string, pushProtection string) *github.SecurityAndAnalysis {
+	return &github.SecurityAndAnalysis{
+		DependabotSecurityUpdates:    &github.DependabotSecurityUpdates{Status: &dependabot},
+		SecretScanning:               &github.SecretScanning{Status: &secretScanning},
+		SecretScanningPushProtection: &github.SecretScanningPushProtection{Status: &pushProtection},
+	}
+}
+
+func (suite *SecuritySettingsSuite) TestAllFeaturesEnabled() {
+	existing := suite.createSecurityAndAnalysis("enabled", "enabled", "enabled")
+	suite.Empty(diffSecurityAndAnalysis(existing, defaultRepoSettingsPolicy().Security, "enabled"))
+}
+
+func (suite *SecuritySettingsSuite) TestDisabledFeatures() {
+	existing := suite.createSecurityAndAnalysis("enabled", "disabled", "disabled")
+	suite.Equal([]protectionDifference{
+		{attribute: "secret scanning", expected: "enabled", actual: "disabled"},
+		{attribute: "secret scanning push protection", expected: "enabled", actual: "disabled"},
+		{attribute: "private vulnerability reporting", expected: "enabled", actual: "disabled"},
+	}, diffSecurityAndAnalysis(existing, defaultRepoSettingsPolicy().Security, "disabled"))
+}
+
+func (suite *SecuritySettingsSuite) TestFeaturesThatAreNotRequired() {
+	existing := suite.createSecurityAndAnalysis("disabled", "disabled", "disabled")
+	suite.Empty(diffSecurityAndAnalysis(existing, SecuritySettingsPolicy{VulnerabilityAlerts: true}, "disabled"))
+}
+
+func (suite *SecuritySettingsSuite) TestMissingSecurityAndAnalysis() {
+	differences := diffSecurityAndAnalysis(nil, SecuritySettingsPolicy{SecretScanning: true}, "disabled")
+	suite.Equal([]protectionDifference{{attribute: "secret scanning", expected: "enabled", actual: "unknown"}}, differences)
+}
+
+func (suite *SecuritySettingsSuite) TestUnknownStatusIsUnavailable() {
+	existing := suite.createSecurityAndAnalysis("enabled", "", "disabled")
+	disabled, unavailable := splitUnavailableFeatures(diffSecurityAndAnalysis(existing, defaultRepoSettingsPolicy().Security, "enabled"))
+	suite.Equal([]protectionDifference{{attribute: "secret scanning push protection", expected: "enabled", actual: "disabled"}}, disabled)
+	suite.Equal([]protectionDifference{{attribute: "secret scanning", expected: "enabled", actual: "unknown"}}, unavailable)
+}
+
+func (suite *SecuritySettingsSuite) TestUnexpectedStatusIsUnavailable() {
+	existing := suite.createSecurityAndAnalysis("enabled", "enabled", "enabled")
+	disabled, unavailable := splitUnavailableFeatures(diffSecurityAndAnalysis(existing, defaultRepoSettingsPolicy().Security, "not-available"))
+	suite.Empty(disabled)
+	suite.Equal([]protectionDifference{{attribute: "private vulnerability reporting", expected: "enabled", actual: "not-available"}}, unavailable)
+}
+
+func (suite *SecuritySettingsSuite) TestWarnsAboutUnavailableFeaturesWithoutEnablingThem() {
+	policy := RepoSettingsPolicy{Security: SecuritySettingsPolicy{SecretScanning: true}}
+	report := &findingsReport{}
+	verifier := RepoSettingsVerifier{repo: "my-repo", policy: &policy, report: report}
+	handler := &recordingRepoProblemHandler{}
+	verifier.verifySecurityAndAnalysis(&github.Repository{}, handler)
+	suite.Empty(handler.enabledDifferences)
+	suite.Len(report.findings, 1)
+	suite.Equal(unavailableSecurityFeatureFinding, report.findings[0].Kind)
+	suite.Equal("unknown", report.findings[0].Actual)
+}
+
+func (suite *SecuritySettingsSuite) TestFailedFixIsWarning() {
+	policy := RepoSettingsPolicy{Security: SecuritySettingsPolicy{SecretScanning: true}}
+	report := &findingsReport{}
+	verifier := RepoSettingsVerifier{repo: "my-repo", policy: &policy, report: report}
+	handler := &recordingRepoProblemHandler{err: errors.New("422 secret scanning is not available")}
+	disabled := "disabled"
+	repo := &github.Repository{SecurityAndAnalysis: &github.SecurityAndAnalysis{SecretScanning: &github.SecretScanning{Status: &disabled}}}
+	verifier.verifySecurityAndAnalysis(repo, handler)
+	suite.Len(handler.enabledDifferences, 1)
+	suite.Equal([]string{settingsDriftFinding, unavailableSecurityFeatureFinding}, []string{report.findings[0].Kind, report.findings[1].Kind})
+}
+
+// recordingRepoProblemHandler records the security features that the verifier tries to enable.
+type recordingRepoProblemHandler struct {
+	LogRepoProblemHandler
+	enabledDifferences []protectionDifference
+	err                error
+}
+
+func (handler *recordingRepoProblemHandler) handleDisabledSecurityFeatures(request *github.SecurityAndAnalysis, differences []protectionDifference) error {
+	handler.enabledDifferences = append(handler.enabledDifferences, differences...)
+	return handler.err
+}
+
+func (suite *SecuritySettingsSuite) TestRequestEnablesOnlyDisabledFeatures() {
+	enabled := "enabled"
+	request := createSecurityAndAnalysisRequest([]protectionDifference{
+		{attribute: "secret scanning push protection", expected: "enabled", actual: "disabled"},
+		{attribute: "private vulnerability reporting", expected: "enabled", actual: "disabled"},
+	})
+	suite.Equal(&github.SecurityAndAnalysis{SecretScanningPushProtection: &github.SecretScanningPushProtection{Status: &enabled}}, request)
+}
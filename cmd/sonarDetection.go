@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultSonarCheckName = "SonarCloud Code Analysis"
+
+// sonarCheckRunHistory is the number of recent commits in which sonarDetector looks for a SonarCloud check run.
+const sonarCheckRunHistory = 5
+
+// sonarPomPattern matches the Sonar Maven plugin and Sonar properties. The property sonar.skip is excluded by
+// hasSonarPomConfiguration.
+var sonarPomPattern = regexp.MustCompile(`sonar-maven-plugin|<sonar\.([a-zA-Z.]+)>`)
+var sonarPomSkipPattern = regexp.MustCompile(`<sonar\.skip>\s*true\s*</sonar\.skip>`)
+var xmlCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// sonarActionPattern matches the scanner actions of SonarSource, e.g. SonarSource/sonarcloud-github-action.
+var sonarActionPattern = regexp.MustCompile(`(?i)^sonarsource/`)
+var sonarScanCommandPattern = regexp.MustCompile(`\bsonar:sonar\b|sonar-maven-plugin|\bsonar-scanner\b`)
+var sonarSkipPattern = regexp.MustCompile(`sonar\.skip\s*=\s*true`)
+var shellCommentPattern = regexp.MustCompile(`(?m)^\s*#.*$`)
+
+// sonarDetector decides if a branch requires the SonarCloud check based on evidence in the repository instead of its
+// language.
+type sonarDetector struct {
+	source repositoryContentSource
+	// getRecentCheckRuns returns the names of the check runs of the recent commits. It may be nil.
+	getRecentCheckRuns func() []string
+	checkName          string
+}
+
+// findEvidence returns a description of the evidence that the repository uses SonarCloud or "" if there is none.
+func (detector sonarDetector) findEvidence() string {
+	if detector.fileExists("sonar-project.properties") {
+		return "sonar-project.properties"
+	}
+	pom, found := readOptionalContent(detector.source, "pom.xml")
+	if found && hasSonarPomConfiguration(pom) {
+		return "Sonar configuration in pom.xml"
+	}
+	if workflowFile := detector.findWorkflowWithSonarStep(); workflowFile != "" {
+		return "Sonar step in " + workflowFile
+	}
+	if detector.getRecentCheckRuns != nil && containsString(detector.getRecentCheckRuns(), detector.checkName) {
+		return fmt.Sprintf("'%v' check run on recent commits", detector.checkName)
+	}
+	return ""
+}
+
+func newSonarCheck(checkName string, appId int64, evidence string) requiredCheck {
+	return requiredCheck{context: checkName, origin: "SonarCloud, detected by " + evidence, appId: appId}
+}
+
+func (detector sonarDetector) fileExists(path string) bool {
+	_, found := readOptionalContent(detector.source, path)
+	return found
+}
+
+func (detector sonarDetector) findWorkflowWithSonarStep() string {
+	workflowFiles, err := detector.source.listWorkflowFiles()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to list workflow files. Cause: %v", err.Error()))
+	}
+	for _, workflowFile := range workflowFiles {
+		content, found := readOptionalContent(detector.source, workflowFile)
+		if found && hasSonarStep(content) {
+			return workflowFile
+		}
+	}
+	return ""
+}
+
+// hasSonarPomConfiguration checks if the pom configures the Sonar Maven plugin or Sonar properties without skipping the
+// analysis. Comments are ignored.
+func hasSonarPomConfiguration(pom string) bool {
+	pom = xmlCommentPattern.ReplaceAllString(pom, "")
+	if sonarPomSkipPattern.MatchString(pom) {
+		return false
+	}
+	for _, match := range sonarPomPattern.FindAllStringSubmatch(pom, -1) {
+		if match[1] != "skip" {
+			return true
+		}
+	}
+	return false
+}
+
+// isSonarScanCommand checks if the run command of a step starts a Sonar scan that is not skipped. Comments are ignored.
+func isSonarScanCommand(command string) bool {
+	command = shellCommentPattern.ReplaceAllString(command, "")
+	return sonarScanCommandPattern.MatchString(command) && !sonarSkipPattern.MatchString(command)
+}
+
+// hasSonarStep checks if a job of the workflow uses a SonarSource scanner action or runs a Sonar scan. Invalid workflows
+// are ignored since lint-workflows reports them.
+func hasSonarStep(workflowContent string) bool {
+	var workflow workflowDefinitionInt
+	if yaml.Unmarshal([]byte(workflowContent), &workflow) != nil {
+		return false
+	}
+	for _, job := range workflow.Jobs {
+		for _, step := range job.Steps {
+			if (step.Uses != nil && sonarActionPattern.MatchString(*step.Uses)) || (step.Run != nil && isSonarScanCommand(*step.Run)) {
+				return true
+			}
+		}
+	}
+	return false
+}
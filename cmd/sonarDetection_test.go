@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SonarDetectionSuite struct {
+	repositoryFixture
+}
+
+func TestSonarDetectionSuite(t *testing.T) {
+	suite.Run(t, new(SonarDetectionSuite))
+}
+
+func (suite *SonarDetectionSuite) SetupTest() {
+	suite.repositoryFixture.SetupTest()
+	suite.writeFile(".github/workflows/ci-build.yml", `
+on: [pull_request]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: go test ./...
+`)
+}
+
+func (suite *SonarDetectionSuite) findEvidence(checkRuns ...string) string {
+	detector := sonarDetector{source: suite.source(), checkName: defaultSonarCheckName,
+		getRecentCheckRuns: func() []string { return checkRuns }}
+	return detector.findEvidence()
+}
+
+func (suite *SonarDetectionSuite) TestNoEvidence() {
+	suite.writeFile("pom.xml", "<project><artifactId>plain</artifactId></project>")
+	suite.Equal("", suite.findEvidence("build"))
+}
+
+func (suite *SonarDetectionSuite) TestSonarProjectProperties() {
+	suite.writeFile("sonar-project.properties", "sonar.projectKey=com.exasol:project")
+	suite.Equal("sonar-project.properties", suite.findEvidence())
+}
+
+func (suite *SonarDetectionSuite) TestSonarPluginInPom() {
+	suite.writeFile("pom.xml", "<project><properties><sonar.organization>exasol</sonar.organization></properties></project>")
+	suite.Equal("Sonar configuration in pom.xml", suite.findEvidence())
+}
+
+func (suite *SonarDetectionSuite) TestSonarStepInWorkflow() {
+	suite.writeFile(".github/workflows/ci-build.yml", `
+on: [pull_request]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: SonarSource/sonarcloud-github-action@v2
+`)
+	suite.Equal("Sonar step in .github/workflows/ci-build.yml", suite.findEvidence())
+}
+
+func (suite *SonarDetectionSuite) TestSonarScanInRunStep() {
+	suite.writeFile(".github/workflows/ci-build.yml", `
+on: [pull_request]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: mvn verify sonar:sonar
+`)
+	suite.Equal("Sonar step in .github/workflows/ci-build.yml", suite.findEvidence())
+}
+
+func (suite *SonarDetectionSuite) TestPastCheckRun() {
+	suite.Equal("'SonarCloud Code Analysis' check run on recent commits", suite.findEvidence("build", "SonarCloud Code Analysis"))
+}
+
+func (suite *SonarDetectionSuite) TestSkippedSonarScanInRunStep() {
+	suite.writeFile(".github/workflows/ci-build.yml", `
+on: [pull_request]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: mvn verify -Dsonar.skip=true
+      - run: |
+          # sonar:sonar is executed by the release workflow
+          mvn verify sonar:sonar -Dsonar.skip=true
+`)
+	suite.Equal("", suite.findEvidence())
+}
+
+func (suite *SonarDetectionSuite) TestOtherActionMentioningSonar() {
+	suite.writeFile(".github/workflows/ci-build.yml", `
+on: [pull_request]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: exasol/sonar-report-action@v1
+`)
+	suite.Equal("", suite.findEvidence())
+}
+
+func (suite *SonarDetectionSuite) TestSonarSkipInPom() {
+	suite.writeFile("pom.xml", "<project><properties><sonar.skip>true</sonar.skip></properties></project>")
+	suite.Equal("", suite.findEvidence())
+}
+
+func (suite *SonarDetectionSuite) TestSonarPropertyInPomComment() {
+	suite.writeFile("pom.xml", "<project><!-- <sonar.organization>exasol</sonar.organization> --></project>")
+	suite.Equal("", suite.findEvidence())
+}
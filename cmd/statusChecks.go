@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// githubActionsAppId is the ID of the GitHub app that reports the checks of workflow jobs.
+const githubActionsAppId int64 = 15368
+
+// sonarCloudAppId is the ID of the GitHub app of SonarCloud.
+const sonarCloudAppId int64 = 12526
+
+// anyAppId allows every app and user to report a required check.
+const anyAppId int64 = -1
+
+var knownAppNames = map[int64]string{githubActionsAppId: "GitHub Actions", sonarCloudAppId: "SonarCloud"}
+
+// toRequiredStatusChecks converts the checks into status checks that are bound to the app of each check. Checks without
+// app are not bound.
+func toRequiredStatusChecks(checks []requiredCheck) []*github.RequiredStatusCheck {
+	result := make([]*github.RequiredStatusCheck, 0, len(checks))
+	for _, check := range checks {
+		statusCheck := &github.RequiredStatusCheck{Context: check.context}
+		if check.appId != 0 {
+			appId := check.appId
+			statusCheck.AppID = &appId
+		}
+		result = append(result, statusCheck)
+	}
+	return result
+}
+
+// getExistingStatusChecks returns the required checks of an existing protection. Protections that only list contexts
+// are not bound to apps.
+func getExistingStatusChecks(statusChecks *github.RequiredStatusChecks) []*github.RequiredStatusCheck {
+	if statusChecks == nil {
+		return nil
+	}
+	if len(statusChecks.Checks) > 0 {
+		return statusChecks.Checks
+	}
+	result := make([]*github.RequiredStatusCheck, 0, len(statusChecks.Contexts))
+	for _, context := range statusChecks.Contexts {
+		result = append(result, &github.RequiredStatusCheck{Context: context})
+	}
+	return result
+}
+
+func getStatusCheckContexts(checks []*github.RequiredStatusCheck) []string {
+	var result []string
+	for _, check := range checks {
+		result = append(result, check.Context)
+	}
+	return result
+}
+
+func findStatusCheck(checks []*github.RequiredStatusCheck, context string) *github.RequiredStatusCheck {
+	for _, check := range checks {
+		if check.Context == context {
+			return check
+		}
+	}
+	return nil
+}
+
+// isSameAppBinding checks if the existing app ID fulfills the expected one. An expected check without app is fulfilled
+// by any binding.
+func isSameAppBinding(expected *int64, existing *int64) bool {
+	return expected == nil || (existing != nil && *existing == *expected)
+}
+
+func describeAppBinding(appId *int64) string {
+	if appId == nil || *appId == anyAppId {
+		return "any app"
+	}
+	if name, found := knownAppNames[*appId]; found {
+		return fmt.Sprintf("app %d (%v)", *appId, name)
+	}
+	return fmt.Sprintf("app %d", *appId)
+}
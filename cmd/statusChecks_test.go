@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/suite"
+)
+
+type StatusChecksSuite struct {
+	suite.Suite
+}
+
+func TestStatusChecksSuite(t *testing.T) {
+	suite.Run(t, new(StatusChecksSuite))
+}
+
+func (suite *StatusChecksSuite) TestToRequiredStatusChecks() {
+	checks := toRequiredStatusChecks([]requiredCheck{{context: "build", appId: githubActionsAppId}, {context: "license/cla"}})
+	suite.Equal("build", checks[0].Context)
+	suite.Equal(githubActionsAppId, checks[0].GetAppID())
+	suite.Nil(checks[1].AppID)
+}
+
+func (suite *StatusChecksSuite) TestGetExistingStatusChecksFromContexts() {
+	checks := getExistingStatusChecks(&github.RequiredStatusChecks{Contexts: []string{"build"}})
+	suite.Equal([]*github.RequiredStatusCheck{{Context: "build"}}, checks)
+}
+
+func (suite *StatusChecksSuite) TestIsSameAppBinding() {
+	actionsAppId := githubActionsAppId
+	anyApp := anyAppId
+	suite.True(isSameAppBinding(nil, &anyApp))
+	suite.True(isSameAppBinding(&actionsAppId, &actionsAppId))
+	suite.False(isSameAppBinding(&actionsAppId, &anyApp))
+	suite.False(isSameAppBinding(&actionsAppId, nil))
+}
+
+func (suite *StatusChecksSuite) TestDescribeAppBinding() {
+	sonarAppId := sonarCloudAppId
+	otherAppId := int64(42)
+	suite.Equal("any app", describeAppBinding(nil))
+	suite.Equal("app 12526 (SonarCloud)", describeAppBinding(&sonarAppId))
+	suite.Equal("app 42", describeAppBinding(&otherAppId))
+}
@@ -3,8 +3,9 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
 
-	"github.com/google/go-github/v43/github"
+	"github.com/google/go-github/v57/github"
 )
 
 func getLabelModifier(fix bool, repo string, githubClient *github.Client) LablesModifier {
@@ -15,7 +16,7 @@ func getLabelModifier(fix bool, repo string, githubClient *github.Client) Lables
 	}
 }
 
-func UnifyLabels(repo string, githubClient *github.Client, fix bool) {
+func UnifyLabels(repo string, githubClient *github.Client, labelPolicy *LabelPolicy, fix bool) {
 	labelDefinitions := []*LabelDesc{
 		{"feature", "88ee66", []string{"enhancement"}, true},
 		{"bug", "ee0000", []string{}, true},
@@ -32,18 +33,18 @@ func UnifyLabels(repo string, githubClient *github.Client, fix bool) {
 		{"security", "ee0000", []string{}, false}, //check if we can configure
 		{"blocked:yes", "000000", []string{"blocked", "status:blocked"}, true}}
 	labelModifier := getLabelModifier(fix, repo, githubClient)
-	unifyLabels(repo, githubClient, labelDefinitions, labelModifier)
+	unifyLabels(repo, githubClient, labelDefinitions, labelPolicy, labelModifier)
 	checkExistingLabels(repo, githubClient, labelDefinitions, labelModifier)
 }
 
-func unifyLabels(repo string, githubClient *github.Client, labelDefinitions []*LabelDesc, labelModifier LablesModifier) {
+func unifyLabels(repo string, githubClient *github.Client, labelDefinitions []*LabelDesc, labelPolicy *LabelPolicy, labelModifier LablesModifier) {
 	labels := listLabels(repo, githubClient)
 	for _, label := range labels {
 		labelDesc := findLabelDefinitionByName(*label.Name, labelDefinitions)
 		if labelDesc == nil {
 			labelDescByOldName := findLabelDefinitionByOldName(*label.Name, labelDefinitions)
 			if labelDescByOldName == nil {
-				labelModifier.removeLabel(label)
+				handleUndefinedLabel(label, labelPolicy, labelModifier)
 			} else {
 				labelModifier.renameLabel(label, labelDescByOldName)
 			}
@@ -51,6 +52,16 @@ func unifyLabels(repo string, githubClient *github.Client, labelDefinitions []*L
 	}
 }
 
+func handleUndefinedLabel(label *github.Label, labelPolicy *LabelPolicy, labelModifier LablesModifier) {
+	if colorFamily := labelPolicy.findColorFamily(*label.Name); colorFamily != nil {
+		if !strings.EqualFold(*label.Color, colorFamily.Color) {
+			labelModifier.setColor(label, &LabelDesc{name: *label.Name, color: colorFamily.Color})
+		}
+	} else if !labelPolicy.isProtected(*label.Name) {
+		labelModifier.removeLabel(label)
+	}
+}
+
 func checkExistingLabels(repo string, githubClient *github.Client, labelDefinitions []*LabelDesc, labelModifier LablesModifier) {
 	labels := listLabels(repo, githubClient) // list again to get renamed
 	for _, labelDefinition := range labelDefinitions {
@@ -60,7 +71,7 @@ func checkExistingLabels(repo string, githubClient *github.Client, labelDefiniti
 				labelModifier.createLabel(labelDefinition)
 			}
 		} else {
-			if *label.Color != labelDefinition.color {
+			if !strings.EqualFold(*label.Color, labelDefinition.color) {
 				labelModifier.setColor(label, labelDefinition)
 			}
 		}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"testing"
 
-	"github.com/google/go-github/v43/github"
+	"github.com/google/go-github/v57/github"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -27,7 +27,7 @@ func (suite *UnifyLabelsSuite) TestCreateLabels() {
 }
 
 func (suite *UnifyLabelsSuite) runUnifyLabelCommand(fix bool) {
-	UnifyLabels(suite.testRepo, suite.githubClient, fix)
+	UnifyLabels(suite.testRepo, suite.githubClient, &DefaultPolicy().Labels, fix)
 }
 
 func (suite *UnifyLabelsSuite) TestRenameLabel() {
@@ -101,6 +101,32 @@ func (suite *UnifyLabelsSuite) TestDeleteLabel() {
 	suite.Assert().NotContains(labelNames, unknownLabel)
 }
 
+func (suite *UnifyLabelsSuite) TestKeepProtectedLabel() {
+	suite.cleanup()       // to be sure we start with a defined state
+	defer suite.cleanup() // to leave a clean repo
+	githubClient := suite.githubClient
+	protectedLabel := "connector:oracle"
+	_, _, err := githubClient.Issues.CreateLabel(context.Background(), suite.testOrg, suite.testRepo, &github.Label{Name: &protectedLabel})
+	suite.NoError(err)
+	UnifyLabels(suite.testRepo, githubClient, &LabelPolicy{Protected: []string{"connector:*"}}, true)
+	_, _, err = githubClient.Issues.GetLabel(context.Background(), suite.testOrg, suite.testRepo, protectedLabel)
+	suite.NoError(err)
+}
+
+func (suite *UnifyLabelsSuite) TestChangeColorOfLabelFamily() {
+	suite.cleanup()       // to be sure we start with a defined state
+	defer suite.cleanup() // to leave a clean repo
+	githubClient := suite.githubClient
+	familyLabel := "area:udf"
+	otherColor := "112233"
+	_, _, err := githubClient.Issues.CreateLabel(context.Background(), suite.testOrg, suite.testRepo, &github.Label{Name: &familyLabel, Color: &otherColor})
+	suite.NoError(err)
+	UnifyLabels(suite.testRepo, githubClient, &LabelPolicy{ColorFamilies: []LabelColorFamily{{Prefix: "area:", Color: "1d76db"}}}, true)
+	label, _, err := githubClient.Issues.GetLabel(context.Background(), suite.testOrg, suite.testRepo, familyLabel)
+	suite.NoError(err)
+	suite.Equal("1d76db", *label.Color)
+}
+
 func (suite *UnifyLabelsSuite) cleanup() {
 	suite.deleteAllLabels()
 	suite.closeAllIssues()
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/suite"
+)
+
+type LabelColorSuite struct {
+	suite.Suite
+}
+
+func TestLabelColorSuite(t *testing.T) {
+	suite.Run(t, new(LabelColorSuite))
+}
+
+// recordingLabelModifier records the labels whose color is changed.
+type recordingLabelModifier struct {
+	DryRunLabelModifier
+	recoloredLabels []string
+}
+
+func (modifier *recordingLabelModifier) setColor(label *github.Label, labelDefinition *LabelDesc) {
+	modifier.recoloredLabels = append(modifier.recoloredLabels, label.GetName())
+}
+
+func (suite *LabelColorSuite) handleLabel(name string, color string) []string {
+	policy := &LabelPolicy{ColorFamilies: []LabelColorFamily{{Prefix: "area:", Color: "1d76db"}}}
+	modifier := &recordingLabelModifier{}
+	handleUndefinedLabel(&github.Label{Name: &name, Color: &color}, policy, modifier)
+	return modifier.recoloredLabels
+}
+
+func (suite *LabelColorSuite) TestColorIsComparedCaseInsensitive() {
+	suite.Empty(suite.handleLabel("area:docs", "1D76DB"))
+}
+
+func (suite *LabelColorSuite) TestWrongColorIsChanged() {
+	suite.Equal([]string{"area:docs"}, suite.handleLabel("area:docs", "ffffff"))
+}
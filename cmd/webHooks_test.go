@@ -5,7 +5,7 @@ import (
 	"sort"
 	"testing"
 
-	"github.com/google/go-github/v43/github"
+	"github.com/google/go-github/v57/github"
 	"github.com/stretchr/testify/suite"
 )
 
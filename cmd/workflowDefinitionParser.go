@@ -1,21 +1,52 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"reflect"
 	"regexp"
 	"strings"
 
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 type WorkflowDefinitionParser struct {
+	// loader loads reusable workflows. If it is nil, jobs that call reusable workflows can't be resolved.
+	loader reusableWorkflowLoader
 }
 
+// reusableWorkflowLoader loads the content of a reusable workflow that a job references via `uses:`.
+type reusableWorkflowLoader interface {
+	loadWorkflow(reference *reusableWorkflowReference) (string, error)
+}
+
+// reusableWorkflowReference is a parsed `uses:` value of a job. For local workflows repo and ref are empty.
+type reusableWorkflowReference struct {
+	repo string
+	path string
+	ref  string
+}
+
+// maxReusableWorkflowDepth is the maximum nesting of reusable workflows supported by GitHub.
+const maxReusableWorkflowDepth = 4
+
 type workflowDefinition struct {
 	Name          string
 	Trigger       *TriggerDefinition
 	rawDefinition *workflowDefinitionInt
+	loader        reusableWorkflowLoader
+	depth         int
+	// reportingEvents are the events whose runs report the checks for pull requests. If it is nil, the events are
+	// derived from the triggers without a branch filter.
+	reportingEvents []string
+}
+
+// unresolvableReusableWorkflowError is returned if the reusable workflow called by a job can't be loaded or parsed.
+type unresolvableReusableWorkflowError struct {
+	message string
+}
+
+func (workflowError unresolvableReusableWorkflowError) Error() string {
+	return workflowError.message
 }
 
 type ValidationError struct {
@@ -36,104 +67,278 @@ func (parser WorkflowDefinitionParser) ParseWorkflowDefinition(content string) (
 	if err != nil {
 		return nil, err
 	}
-	definition := workflowDefinition{Name: parsedYaml.Name, Trigger: trigger, rawDefinition: &parsedYaml}
+	definition := workflowDefinition{Name: parsedYaml.Name, Trigger: trigger, rawDefinition: &parsedYaml, loader: parser.loader}
 	return &definition, nil
 }
 
+// GetJobNames returns the names of the checks reported by the jobs of this workflow. Jobs whose names can't be
+// resolved are skipped. Use GetJobNamesAndFindings to get them as findings.
 func (workflow workflowDefinition) GetJobNames() ([]string, error) {
+	jobNames, _, err := workflow.GetJobNamesAndFindings()
+	return jobNames, err
+}
+
+// workflowCheck is a status check reported by a workflow job.
+type workflowCheck struct {
+	name string
+	// jobKey is the key of the job. For jobs of reusable workflows it contains the keys of the calling and called job.
+	jobKey string
+	// matrix describes the matrix combination of the job, e.g. "os=linux, go=1.19". It is empty for jobs without matrix.
+	matrix string
+}
+
+// GetJobNamesAndFindings returns the names of the checks reported by the jobs of this workflow for pull requests and
+// findings for the jobs whose names can't be resolved, e.g. because their matrix is built by a `fromJSON(...)`
+// expression, and for jobs that are skipped for some or all pull requests.
+func (workflow workflowDefinition) GetJobNamesAndFindings() ([]string, []Finding, error) {
+	checks, findings, err := workflow.getChecksAndFindings()
+	if err != nil {
+		return nil, nil, err
+	}
+	return getWorkflowCheckNames(checks), findings, nil
+}
+
+func (workflow workflowDefinition) getReportingEvents() []string {
+	if workflow.reportingEvents != nil {
+		return workflow.reportingEvents
+	}
+	return workflow.Trigger.getReportingEvents("")
+}
+
+func (workflow workflowDefinition) getChecksAndFindings() ([]workflowCheck, []Finding, error) {
 	jobs := workflow.rawDefinition.Jobs
-	var jobNames []string
+	reportingEvents := workflow.getReportingEvents()
+	skippedJobs := getJobsSkippedOnPullRequests(jobs, reportingEvents)
+	var checks []workflowCheck
+	var findings []Finding
 	for jobKey, jobDescription := range jobs {
-		jobName := getJobName(jobKey, &jobDescription)
-		if jobDescription.Strategy != nil && len(jobDescription.Strategy.Matrix) != 0 {
-			jobNamesForThisJob, err := fillJobNameParametersForMatrixBuild(jobDescription, jobName)
+		if reason, skipped := skippedJobs[jobKey]; skipped {
+			findings = append(findings, Finding{Kind: skippedJobFinding, Job: jobKey, Message: fmt.Sprintf("The checks of this job are not required because %v.", reason)})
+			continue
+		}
+		if class, _ := classifyJobCondition(jobDescription.getCondition(), reportingEvents); class == jobConditional {
+			findings = append(findings, Finding{Kind: conditionalJobFinding, Job: jobKey, Message: fmt.Sprintf("The job has the condition '%v' and might be skipped for some pull requests. Please verify that its checks are reported.", strings.TrimSpace(jobDescription.getCondition()))})
+		}
+		checksOfThisJob, findingsForThisJob, err := workflow.getChecksForJob(jobKey, &jobDescription)
+		var matrixError unresolvableMatrixError
+		var workflowError unresolvableReusableWorkflowError
+		if errors.As(err, &matrixError) {
+			findings = append(findings, Finding{Kind: unresolvableMatrixFinding, Job: jobKey, Message: fmt.Sprintf("The checks of this job can't be resolved because %v. Please add them manually.", matrixError.message)})
+			continue
+		} else if errors.As(err, &workflowError) {
+			findings = append(findings, Finding{Kind: unresolvableReusableWorkflowFinding, Job: jobKey, Message: fmt.Sprintf("The checks of this job can't be resolved because %v. Please add them manually.", workflowError.message)})
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		checks = append(checks, checksOfThisJob...)
+		findings = append(findings, findingsForThisJob...)
+	}
+	return checks, findings, nil
+}
+
+func getWorkflowCheckNames(checks []workflowCheck) []string {
+	var names []string
+	for _, check := range checks {
+		names = append(names, check.name)
+	}
+	return names
+}
+
+func (workflow workflowDefinition) getChecksForJob(jobKey string, jobDescription *JobDescriptionInt) ([]workflowCheck, []Finding, error) {
+	jobName := getJobName(jobKey, jobDescription)
+	checks := []workflowCheck{{name: jobName, jobKey: jobKey}}
+	combinations := []matrixCombination{nil}
+	if jobDescription.Strategy != nil && !jobDescription.Strategy.Matrix.IsZero() {
+		var err error
+		combinations, err = expandMatrix(&jobDescription.Strategy.Matrix)
+		if err != nil {
+			return nil, nil, err
+		}
+		jobNames, err := fillJobNameParametersForMatrixBuild(jobName, combinations)
+		if err != nil {
+			return nil, nil, err
+		}
+		checks = nil
+		for index, name := range jobNames {
+			checks = append(checks, workflowCheck{name: name, jobKey: jobKey, matrix: combinations[index].String()})
+		}
+	}
+	if jobDescription.Uses == nil {
+		return checks, nil, nil
+	}
+	calledChecks, calledFindings, err := workflow.getChecksOfReusableWorkflow(jobDescription)
+	if err != nil {
+		return nil, nil, err
+	}
+	for index := range calledFindings {
+		calledFindings[index].Job = jobKey + " / " + calledFindings[index].Job
+	}
+	combinedChecks, err := combineCallerAndCalledChecks(checks, combinations, calledChecks, jobDescription.With)
+	if err != nil {
+		return nil, nil, err
+	}
+	return combinedChecks, calledFindings, nil
+}
+
+// getChecksOfReusableWorkflow loads the reusable workflow called by the job and returns the checks of its jobs. If the
+// workflow can't be loaded or parsed, it returns an unresolvableReusableWorkflowError, so that only the checks of this
+// job are missing.
+func (workflow workflowDefinition) getChecksOfReusableWorkflow(jobDescription *JobDescriptionInt) ([]workflowCheck, []Finding, error) {
+	uses := *jobDescription.Uses
+	if workflow.loader == nil {
+		return nil, nil, unresolvableReusableWorkflowError{fmt.Sprintf("the reusable workflow '%v' can't be loaded without access to the repository", uses)}
+	}
+	if workflow.depth >= maxReusableWorkflowDepth {
+		return nil, nil, unresolvableReusableWorkflowError{fmt.Sprintf("the reusable workflow '%v' exceeds the maximum nesting depth of %d", uses, maxReusableWorkflowDepth)}
+	}
+	reference, err := parseReusableWorkflowReference(uses)
+	if err != nil {
+		return nil, nil, unresolvableReusableWorkflowError{err.Error()}
+	}
+	content, err := workflow.loader.loadWorkflow(reference)
+	if err != nil {
+		return nil, nil, unresolvableReusableWorkflowError{fmt.Sprintf("the reusable workflow '%v' can't be loaded: %v", uses, err.Error())}
+	}
+	calledWorkflow, err := WorkflowDefinitionParser{loader: workflow.loader}.ParseWorkflowDefinition(content)
+	if err != nil {
+		return nil, nil, unresolvableReusableWorkflowError{fmt.Sprintf("the reusable workflow '%v' can't be parsed: %v", uses, err.Error())}
+	}
+	calledWorkflow.depth = workflow.depth + 1
+	// the jobs of the called workflow run for the events of the caller
+	calledWorkflow.reportingEvents = workflow.getReportingEvents()
+	checks, findings, err := calledWorkflow.getChecksAndFindings()
+	if err != nil {
+		return nil, nil, unresolvableReusableWorkflowError{fmt.Sprintf("the jobs of the reusable workflow '%v' can't be resolved: %v", uses, err.Error())}
+	}
+	return checks, findings, nil
+}
+
+// combineCallerAndCalledChecks creates the checks that GitHub reports for jobs of reusable workflows: "<caller job> / <called job>".
+// The inputs in the names of the called jobs are resolved for the matrix combination of each caller check.
+func combineCallerAndCalledChecks(callerChecks []workflowCheck, callerCombinations []matrixCombination, calledChecks []workflowCheck, inputs map[string]yaml.Node) ([]workflowCheck, error) {
+	var result []workflowCheck
+	for callerIndex, callerCheck := range callerChecks {
+		for _, calledCheck := range calledChecks {
+			calledName, err := replaceInputsInJobName(calledCheck.name, inputs, callerCombinations[callerIndex])
 			if err != nil {
 				return nil, err
 			}
-			jobNames = append(jobNames, jobNamesForThisJob...)
-		} else {
-			jobNames = append(jobNames, jobName)
+			var matrix []string
+			for _, combination := range []string{callerCheck.matrix, calledCheck.matrix} {
+				if combination != "" {
+					matrix = append(matrix, combination)
+				}
+			}
+			result = append(result, workflowCheck{
+				name:   callerCheck.name + " / " + calledName,
+				jobKey: callerCheck.jobKey + " / " + calledCheck.jobKey,
+				matrix: strings.Join(matrix, "; "),
+			})
 		}
 	}
-	return jobNames, nil
+	return result, nil
+}
+
+// inputParameterPattern matches references to inputs. The caller of a nested reusable workflow resolves them.
+var inputParameterPattern = regexp.MustCompile(`\$\{\{\s*inputs\.[\w-]+\s*\}\}`)
+
+// replaceInputsInJobName replaces references to the inputs of a reusable workflow by the values passed by the caller.
+// Matrix parameters in the values are resolved for the given matrix combination of the caller. Other expressions can
+// only be evaluated at runtime.
+func replaceInputsInJobName(jobName string, inputs map[string]yaml.Node, combination matrixCombination) (string, error) {
+	for inputName, inputValue := range inputs {
+		pattern := regexp.MustCompile(`\$\{\{\s*inputs\.` + regexp.QuoteMeta(inputName) + `\s*\}\}`)
+		if !pattern.MatchString(jobName) {
+			continue
+		}
+		value := replaceParametersInJobName(inputValue.Value, combination)
+		if strings.Contains(inputParameterPattern.ReplaceAllString(value, ""), "${{") {
+			return "", unresolvableMatrixError{fmt.Sprintf("the input '%v' of the reusable workflow is defined by the expression '%v' that can only be evaluated at runtime", inputName, inputValue.Value)}
+		}
+		jobName = pattern.ReplaceAllLiteralString(jobName, value)
+	}
+	return jobName, nil
 }
 
-func fillJobNameParametersForMatrixBuild(jobDescription JobDescriptionInt, jobName string) (jobNames []string, err error) {
-	matrix := jobDescription.Strategy.Matrix
-	keys := make([]string, 0, len(matrix))
-	for key := range matrix {
-		keys = append(keys, key)
+// parseReusableWorkflowReference parses `uses:` values of local (./.github/workflows/x.yml) and same organization
+// (exasol/repo/.github/workflows/x.yml@ref) reusable workflows.
+func parseReusableWorkflowReference(uses string) (*reusableWorkflowReference, error) {
+	if strings.HasPrefix(uses, "./") {
+		return &reusableWorkflowReference{path: strings.TrimPrefix(uses, "./")}, nil
 	}
-	if strings.Contains(jobName, "${{") {
-		jobNames = append(jobNames, replaceParametersInJobName(jobName, matrix, keys, 0)...)
-	} else {
-		if len(keys) == 1 {
-			filledNames, err := addParametersToJobName(jobName, matrix[keys[0]])
+	pathAndRef := strings.SplitN(uses, "@", 2)
+	pathParts := strings.SplitN(pathAndRef[0], "/", 3)
+	if len(pathAndRef) != 2 || len(pathParts) != 3 {
+		return nil, fmt.Errorf("the reusable workflow reference '%v' is invalid", uses)
+	}
+	if pathParts[0] != "exasol" {
+		return nil, fmt.Errorf("the reusable workflow '%v' belongs to another organization and is not supported", uses)
+	}
+	return &reusableWorkflowReference{repo: pathParts[1], path: pathParts[2], ref: pathAndRef[1]}, nil
+}
+
+func fillJobNameParametersForMatrixBuild(jobName string, combinations []matrixCombination) (jobNames []string, err error) {
+	for _, combination := range combinations {
+		if strings.Contains(jobName, "${{") {
+			jobNames = append(jobNames, replaceParametersInJobName(jobName, combination))
+		} else {
+			filledName, err := addParametersToJobName(jobName, combination)
 			if err != nil {
 				return nil, err
 			}
-			jobNames = append(jobNames, filledNames...)
-		} else {
-			return nil, ValidationError{"multi dimensional matrix github-action jobs with no explicit name are not supported. Please add a name field to the job that combines the matrix parameters into a more readable name. For example \"Build with Go ${{matrix.go}} and Exasol ${{ matrix.db }}\""}
+			jobNames = append(jobNames, filledName)
 		}
 	}
 	return jobNames, nil
 }
 
-func addParametersToJobName(jobName string, parameterValues []interface{}) (result []string, err error) {
-	for _, value := range parameterValues {
-		_, isMap := value.(map[interface{}]interface{})
-		if isMap {
-			return nil, ValidationError{"matrix github-action jobs with object parameters and no job name are not supported. Please add a name field to the job that combines the matrix parameters into a more readable name. For example \"Build with Go ${{matrix.go}} and Exasol ${{ matrix.db }}\""}
+func addParametersToJobName(jobName string, combination matrixCombination) (string, error) {
+	for _, entry := range combination {
+		if _, isObject := entry.value.(matrixObject); isObject {
+			return "", ValidationError{"matrix github-action jobs with object parameters and no job name are not supported. Please add a name field to the job that combines the matrix parameters into a more readable name. For example \"Build with Go ${{matrix.go}} and Exasol ${{ matrix.db }}\""}
 		}
-		extendedJobName := jobName + " (" + convertValueToString(value) + ")"
-		result = append(result, extendedJobName)
 	}
-	return result, nil
+	if len(combination) != 1 {
+		return "", ValidationError{"multi dimensional matrix github-action jobs with no explicit name are not supported. Please add a name field to the job that combines the matrix parameters into a more readable name. For example \"Build with Go ${{matrix.go}} and Exasol ${{ matrix.db }}\""}
+	}
+	return jobName + " (" + formatMatrixValue(combination[0].value) + ")", nil
 }
 
-func replaceParametersInJobName(jobName string, matrix map[string][]interface{}, keys []string, parameterCursor int) (result []string) {
-	if parameterCursor >= len(keys) {
-		result = append(result, jobName)
-	} else {
-		key := keys[parameterCursor]
-		pattern, err := regexp.Compile("\\${\\{\\s*matrix.\\Q" + key + "\\E\\s*\\}\\}")
-		if err != nil {
-			panic(err)
-		}
-		for _, value := range matrix[key] {
-			filledJobName := replaceSpecificParameterInJobName(jobName, value, pattern)
-			result = append(result, replaceParametersInJobName(filledJobName, matrix, keys, parameterCursor+1)...)
-		}
+// unusedMatrixParameterPattern matches references to matrix parameters that are not part of a combination. GitHub
+// replaces them by an empty string.
+var unusedMatrixParameterPattern = regexp.MustCompile(`\$\{\{\s*matrix\.[\w.-]+\s*\}\}`)
+
+func replaceParametersInJobName(jobName string, combination matrixCombination) string {
+	for _, entry := range combination {
+		jobName = replaceSpecificParameterInJobName(jobName, entry.key, entry.value)
 	}
-	return result
+	return unusedMatrixParameterPattern.ReplaceAllString(jobName, "")
 }
 
-func replaceSpecificParameterInJobName(jobName string, value interface{}, pattern *regexp.Regexp) string {
-	switch value := value.(type) {
-	case string:
-		return pattern.ReplaceAllString(jobName, value)
-	case float64:
-		return pattern.ReplaceAllString(jobName, fmt.Sprintf("%.1f", value))
-	case int:
-		return pattern.ReplaceAllString(jobName, fmt.Sprintf("%d", value))
-	case bool:
-		return pattern.ReplaceAllString(jobName, fmt.Sprintf("%t", value))
-	case map[interface{}]interface{}:
+func replaceSpecificParameterInJobName(jobName string, key string, value interface{}) string {
+	if object, isObject := value.(matrixObject); isObject {
 		filledJobName := jobName
-		for objectKey, objectValue := range value {
-			objectKeyString := objectKey.(string)
-			objectValueString := convertValueToString(objectValue)
-			objectPattern, err := regexp.Compile("\\${\\{\\s*matrix.\\Q" + objectKeyString + "\\E\\s*\\}\\}")
-			if err != nil {
-				panic(err)
-			}
-			filledJobName = objectPattern.ReplaceAllString(filledJobName, objectValueString)
+		for _, objectEntry := range object {
+			objectValueString := formatMatrixValue(objectEntry.value)
+			filledJobName = getMatrixParameterPattern(key+"."+objectEntry.key).ReplaceAllString(filledJobName, objectValueString)
+			filledJobName = getMatrixParameterPattern(objectEntry.key).ReplaceAllString(filledJobName, objectValueString)
 		}
 		return filledJobName
-	default:
-		panic(fmt.Sprintf("unsupported type %v", reflect.TypeOf(value)))
 	}
+	return getMatrixParameterPattern(key).ReplaceAllString(jobName, formatMatrixValue(value))
+}
+
+func getMatrixParameterPattern(key string) *regexp.Regexp {
+	return regexp.MustCompile(`\$\{\{\s*matrix\.` + regexp.QuoteMeta(key) + `\s*\}\}`)
+}
+
+// formatMatrixValue returns the original text of a scalar matrix value. Objects are rendered as "Object".
+func formatMatrixValue(value interface{}) string {
+	if text, isString := value.(string); isString {
+		return text
+	}
+	return "Object"
 }
 
 func convertValueToString(value interface{}) string {
@@ -148,92 +353,45 @@ func getJobName(jobKey string, jobDescription *JobDescriptionInt) string {
 	}
 }
 
-type TriggerDefinition struct {
-	TriggerOnPr              bool
-	TriggerOnPushToBranches  []string
-	TriggerOnPushToAnyBranch bool
+type strategyDescriptionInt struct {
+	Matrix yaml.Node `yaml:"matrix"`
 }
 
-func getTriggersOfWorkflowDefinition(parsedYaml *workflowDefinitionInt) (*TriggerDefinition, error) {
-	if triggersFromArray := tryReadingTriggersFromArray(parsedYaml.On); triggersFromArray != nil {
-		return triggersFromArray, nil
-	} else if triggersFromMap := tryReadingTriggersFromMap(parsedYaml.On); triggersFromMap != nil {
-		return triggersFromMap, nil
-	} else {
-		return nil, fmt.Errorf("the GitHub workflow '%v'uses an unsupported trigger definition style", parsedYaml.Name)
-	}
+type JobDescriptionInt struct {
+	Strategy *strategyDescriptionInt `yaml:"strategy"`
+	Name     *string                 `yaml:"name"`
+	Uses     *string                 `yaml:"uses"`
+	With     map[string]yaml.Node    `yaml:"with"`
+	If       *string                 `yaml:"if"`
+	Needs    interface{}             `yaml:"needs"`
+	// The following fields are only used by the workflow linter
+	RunsOn         interface{}       `yaml:"runs-on"`
+	TimeoutMinutes interface{}       `yaml:"timeout-minutes"`
+	Permissions    interface{}       `yaml:"permissions"`
+	Steps          []stepDescription `yaml:"steps"`
 }
 
-func tryReadingTriggersFromArray(parsedYaml interface{}) *TriggerDefinition {
-	triggersAsList, ok := parsedYaml.([]interface{})
-	if ok && triggersAsList != nil {
-		var result TriggerDefinition
-		for _, trigger := range triggersAsList {
-			lowerTrigger := strings.ToLower(trigger.(string))
-			if lowerTrigger == "push" {
-				result.TriggerOnPushToAnyBranch = true
-			} else if lowerTrigger == "pull_request" {
-				result.TriggerOnPr = true
-			}
-		}
-		return &result
-	}
-	return nil
-}
-
-func tryReadingTriggersFromMap(parsedYaml interface{}) *TriggerDefinition {
-	triggersAsMap, ok := parsedYaml.(map[interface{}]interface{})
-	if ok {
-		var result TriggerDefinition
-		for trigger, triggerParams := range triggersAsMap {
-			lowerTrigger := strings.ToLower(trigger.(string))
-			if lowerTrigger == "pull_request" {
-				result.TriggerOnPr = true
-			} else if lowerTrigger == "push" {
-				branches := readBranchesList(triggerParams)
-				if branches == nil {
-					result.TriggerOnPushToAnyBranch = true
-				} else {
-					result.TriggerOnPushToBranches = append(result.TriggerOnPushToBranches, branches...)
-				}
-			}
-		}
-		return &result
-	}
-	return nil
+type stepDescription struct {
+	Name *string `yaml:"name"`
+	Uses *string `yaml:"uses"`
+	Run  *string `yaml:"run"`
 }
 
-func readBranchesList(triggerParams interface{}) []string {
-	triggerParamMap, ok := triggerParams.(map[interface{}]interface{})
-	if !ok {
-		return nil
-	}
-	branches := triggerParamMap["branches"]
-	if branches == nil {
-		return nil
-	}
-	branchesList, ok := branches.([]interface{})
-	if !ok {
-		return nil
-	}
-	var result = make([]string, 0, len(branchesList))
-	for _, branch := range branchesList {
-		result = append(result, branch.(string))
+func (job *JobDescriptionInt) getCondition() string {
+	if job.If == nil {
+		return ""
 	}
-	return result
+	return *job.If
 }
 
-type strategyDescriptionInt struct {
-	Matrix map[string][]interface{} `yaml:"matrix"`
-}
-
-type JobDescriptionInt struct {
-	Strategy *strategyDescriptionInt `yaml:"strategy"`
-	Name     *string                 `yaml:"name"`
+// getNeeds returns the keys of the jobs that this job depends on. In the workflow they can be a single string or a list.
+func (job *JobDescriptionInt) getNeeds() []string {
+	return readStringList(job.Needs)
 }
 
 type workflowDefinitionInt struct {
-	Name string                       `yaml:"name"`
-	On   interface{}                  `yaml:"on"`
-	Jobs map[string]JobDescriptionInt `yaml:"jobs"`
+	Name        string                       `yaml:"name"`
+	On          interface{}                  `yaml:"on"`
+	Permissions interface{}                  `yaml:"permissions"`
+	Jobs        map[string]JobDescriptionInt `yaml:"jobs"`
 }
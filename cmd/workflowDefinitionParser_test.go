@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -359,9 +360,9 @@ jobs:
 	suite.Contains(jobNames, "Build SSL-Cert with Python 3.9 and Exasol 7.1.6")
 }
 
-func (suite *WorkflowDefinitionParserSuite) TestGetChecksForWorkflowForUnsupportedSyntax() {
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForWorkflowWithMatrixFromJson() {
 	parser := WorkflowDefinitionParser{}
-	_, err := parser.ParseWorkflowDefinition(`
+	definition, err := parser.ParseWorkflowDefinition(`
 name: CI Build
 on:
   push:
@@ -371,8 +372,104 @@ jobs:
       matrix:
         test-path: ${{fromJson(needs.prep-testbed.outputs.matrix)}}
     runs-on: ubuntu-latest
+  prep-testbed:
+    runs-on: ubuntu-latest
+`)
+	suite.NoError(err)
+	jobNames, findings, err := definition.GetJobNamesAndFindings()
+	suite.NoError(err)
+	suite.Equal([]string{"prep-testbed"}, jobNames)
+	suite.Equal([]Finding{{Kind: unresolvableMatrixFinding, Job: "build", Message: "The checks of this job can't be resolved because the matrix parameter 'test-path' is defined by the expression '${{fromJson(needs.prep-testbed.outputs.matrix)}}' that can only be evaluated at runtime. Please add them manually."}}, findings)
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForWorkflowWithMatrixExpression() {
+	parser := WorkflowDefinitionParser{}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  pull_request:
+jobs:
+  build:
+    strategy:
+      matrix: ${{ fromJSON(needs.prepare.outputs.matrix) }}
+    runs-on: ubuntu-latest
+`)
+	suite.NoError(err)
+	jobNames, findings, err := definition.GetJobNamesAndFindings()
+	suite.NoError(err)
+	suite.Empty(jobNames)
+	suite.Len(findings, 1)
+	suite.Equal("build", findings[0].Job)
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForMatrixWithExclude() {
+	parser := WorkflowDefinitionParser{}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  pull_request:
+jobs:
+  build:
+    strategy:
+      matrix:
+        os: [linux, windows]
+        go: ["1.18", "1.19"]
+        exclude:
+          - os: windows
+            go: "1.18"
+    name: Build ${{ matrix.os }} ${{ matrix.go }}
 `)
-	suite.Assert().Error(err)
+	suite.NoError(err)
+	jobNames, err := definition.GetJobNames()
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"Build linux 1.18", "Build linux 1.19", "Build windows 1.19"}, jobNames)
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForMatrixWithIncludeExtendingAndAddingCombinations() {
+	// example from https://docs.github.com/en/actions/using-jobs/using-a-matrix-for-your-jobs#example-expanding-configurations
+	parser := WorkflowDefinitionParser{}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  pull_request:
+jobs:
+  build:
+    strategy:
+      matrix:
+        fruit: [apple, pear]
+        animal: [cat, dog]
+        include:
+          - color: green
+          - color: pink
+            animal: cat
+          - fruit: apple
+            shape: circle
+          - fruit: banana
+          - fruit: banana
+            animal: cat
+    name: ${{ matrix.fruit }}-${{ matrix.animal }}-${{ matrix.color }}-${{ matrix.shape }}
+`)
+	suite.NoError(err)
+	jobNames, err := definition.GetJobNames()
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"apple-cat-pink-circle", "apple-dog-green-circle", "pear-cat-pink-", "pear-dog-green-",
+		"banana---", "banana-cat--"}, jobNames)
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForMatrixWithExcludeOfAllCombinations() {
+	parser := WorkflowDefinitionParser{}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  pull_request:
+jobs:
+  build:
+    strategy:
+      matrix:
+        os: [linux]
+        exclude:
+          - os: linux
+`)
+	suite.NoError(err)
+	jobNames, err := definition.GetJobNames()
+	suite.NoError(err)
+	suite.Empty(jobNames)
 }
 
 func (suite *WorkflowDefinitionParserSuite) TestGetChecksForWorkflowWithFloatParameter() {
@@ -397,8 +494,25 @@ func (suite *WorkflowDefinitionParserSuite) TestGetChecksForWorkflowWithFloatPar
 	suite.NoError(err)
 	suite.Len(jobNames, 3)
 	suite.Contains(jobNames, "Run Tests (Python-3.6, Exasol-7.1.6)")
-	suite.Contains(jobNames, "Run Tests (Python-3.7, Exasol-7.1.6)")
-	suite.Contains(jobNames, "Run Tests (Python-3.9, Exasol-7.1.6)")
+	suite.Contains(jobNames, "Run Tests (Python-3.72, Exasol-7.1.6)")
+	suite.Contains(jobNames, "Run Tests (Python-3.86, Exasol-7.1.6)")
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForWorkflowKeepsLiteralParameterValues() {
+	parser := WorkflowDefinitionParser{}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  pull_request:
+jobs:
+  build:
+    strategy:
+      matrix:
+        value: [3.10, 1e3, 011, 0x1F, True, "quoted", ~]
+`)
+	suite.NoError(err)
+	jobNames, err := definition.GetJobNames()
+	suite.NoError(err)
+	suite.Equal([]string{"build (3.10)", "build (1e3)", "build (011)", "build (0x1F)", "build (True)", "build (quoted)", "build ()"}, jobNames)
 }
 
 func (suite *WorkflowDefinitionParserSuite) TestGetChecksForWorkflowWithIntParameter() {
@@ -448,3 +562,196 @@ func (suite *WorkflowDefinitionParserSuite) TestGetChecksForWorkflowWithBoolPara
 	suite.Len(jobNames, 1)
 	suite.Contains(jobNames, "Run Tests (Success-true, Exasol-7.1.6)")
 }
+
+type inMemoryWorkflowLoader struct {
+	workflows map[string]string
+}
+
+func (loader inMemoryWorkflowLoader) loadWorkflow(reference *reusableWorkflowReference) (string, error) {
+	content, found := loader.workflows[reference.repo+":"+reference.path]
+	if !found {
+		return "", fmt.Errorf("workflow %v not found", reference.path)
+	}
+	return content, nil
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForLocalReusableWorkflow() {
+	parser := WorkflowDefinitionParser{loader: inMemoryWorkflowLoader{workflows: map[string]string{":.github/workflows/build.yml": `
+on: workflow_call
+jobs:
+  compile:
+    name: Compile
+    runs-on: ubuntu-latest
+  test:
+    runs-on: ubuntu-latest
+`}}}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  - pull_request
+jobs:
+  build:
+    name: Build
+    uses: ./.github/workflows/build.yml
+`)
+	suite.NoError(err)
+	jobNames, err := definition.GetJobNames()
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"Build / Compile", "Build / test"}, jobNames)
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForReusableWorkflowOfOtherRepoWithMatrixAndInputs() {
+	parser := WorkflowDefinitionParser{loader: inMemoryWorkflowLoader{workflows: map[string]string{"shared-workflows:.github/workflows/test.yml": `
+on:
+  workflow_call:
+    inputs:
+      project:
+        type: string
+jobs:
+  test:
+    name: Test ${{ inputs.project }} (${{ matrix.db }})
+    strategy:
+      matrix:
+        db: [7, 8]
+    runs-on: ubuntu-latest
+`}}}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  - pull_request
+jobs:
+  call:
+    strategy:
+      matrix:
+        java: [11, 17]
+    uses: exasol/shared-workflows/.github/workflows/test.yml@main
+    with:
+      project: my-project
+`)
+	suite.NoError(err)
+	jobNames, err := definition.GetJobNames()
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"call (11) / Test my-project (7)", "call (11) / Test my-project (8)",
+		"call (17) / Test my-project (7)", "call (17) / Test my-project (8)"}, jobNames)
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForReusableWorkflowWithMatrixInput() {
+	parser := WorkflowDefinitionParser{loader: inMemoryWorkflowLoader{workflows: map[string]string{":.github/workflows/test.yml": `
+on:
+  workflow_call:
+    inputs:
+      python-version:
+        type: string
+jobs:
+  test:
+    name: Test ${{ inputs.python-version }}
+    runs-on: ubuntu-latest
+`}}}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  - pull_request
+jobs:
+  build:
+    strategy:
+      matrix:
+        python: [3.10, 3.11]
+    uses: ./.github/workflows/test.yml
+    with:
+      python-version: ${{ matrix.python }}
+`)
+	suite.NoError(err)
+	jobNames, err := definition.GetJobNames()
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"build (3.10) / Test 3.10", "build (3.11) / Test 3.11"}, jobNames)
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForReusableWorkflowWithRuntimeInput() {
+	parser := WorkflowDefinitionParser{loader: inMemoryWorkflowLoader{workflows: map[string]string{":.github/workflows/test.yml": `
+on:
+  workflow_call:
+    inputs:
+      version:
+        type: string
+jobs:
+  test:
+    name: Test ${{ inputs.version }}
+    runs-on: ubuntu-latest
+`}}}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  - pull_request
+jobs:
+  build:
+    uses: ./.github/workflows/test.yml
+    with:
+      version: ${{ needs.prepare.outputs.version }}
+`)
+	suite.NoError(err)
+	jobNames, findings, err := definition.GetJobNamesAndFindings()
+	suite.NoError(err)
+	suite.Empty(jobNames)
+	suite.Equal([]Finding{{Kind: unresolvableMatrixFinding, Job: "build", Message: "The checks of this job can't be resolved because the input 'version' of the reusable workflow is defined by the expression '${{ needs.prepare.outputs.version }}' that can only be evaluated at runtime. Please add them manually."}}, findings)
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForReusableWorkflowWithoutLoader() {
+	definition, err := WorkflowDefinitionParser{}.ParseWorkflowDefinition(`
+on:
+  - pull_request
+jobs:
+  build:
+    uses: ./.github/workflows/build.yml
+`)
+	suite.NoError(err)
+	jobNames, findings, err := definition.GetJobNamesAndFindings()
+	suite.NoError(err)
+	suite.Empty(jobNames)
+	suite.Equal([]Finding{{Kind: unresolvableReusableWorkflowFinding, Job: "build", Message: "The checks of this job can't be resolved because the reusable workflow './.github/workflows/build.yml' can't be loaded without access to the repository. Please add them manually."}}, findings)
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestUnresolvableReusableWorkflowKeepsChecksOfOtherJobs() {
+	parser := WorkflowDefinitionParser{loader: inMemoryWorkflowLoader{workflows: map[string]string{}}}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  - pull_request
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+  external:
+    uses: other/repo/.github/workflows/build.yml@v1
+  missing:
+    uses: ./.github/workflows/missing.yml
+`)
+	suite.NoError(err)
+	jobNames, findings, err := definition.GetJobNamesAndFindings()
+	suite.NoError(err)
+	suite.Equal([]string{"lint"}, jobNames)
+	suite.ElementsMatch([]Finding{
+		{Kind: unresolvableReusableWorkflowFinding, Job: "external", Message: "The checks of this job can't be resolved because the reusable workflow 'other/repo/.github/workflows/build.yml@v1' belongs to another organization and is not supported. Please add them manually."},
+		{Kind: unresolvableReusableWorkflowFinding, Job: "missing", Message: "The checks of this job can't be resolved because the reusable workflow './.github/workflows/missing.yml' can't be loaded: workflow .github/workflows/missing.yml not found. Please add them manually."},
+	}, findings)
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestGetChecksForRecursiveReusableWorkflow() {
+	parser := WorkflowDefinitionParser{loader: inMemoryWorkflowLoader{workflows: map[string]string{":.github/workflows/build.yml": `
+on: workflow_call
+jobs:
+  build:
+    uses: ./.github/workflows/build.yml
+`}}}
+	definition, err := parser.ParseWorkflowDefinition(`
+on:
+  - pull_request
+jobs:
+  build:
+    uses: ./.github/workflows/build.yml
+`)
+	suite.NoError(err)
+	_, findings, err := definition.GetJobNamesAndFindings()
+	suite.NoError(err)
+	suite.Len(findings, 1)
+	suite.Equal(unresolvableReusableWorkflowFinding, findings[0].Kind)
+	suite.Contains(findings[0].Message, "exceeds the maximum nesting depth of 4")
+}
+
+func (suite *WorkflowDefinitionParserSuite) TestParseReusableWorkflowReferenceOfOtherOrganization() {
+	_, err := parseReusableWorkflowReference("other/repo/.github/workflows/build.yml@v1")
+	suite.ErrorContains(err, "belongs to another organization")
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unresolvableMatrixError is returned if a matrix depends on values that are only known at runtime.
+type unresolvableMatrixError struct {
+	message string
+}
+
+func (matrixError unresolvableMatrixError) Error() string {
+	return matrixError.message
+}
+
+// matrixValue is a parameter of a matrix combination. Its value is either the original text of a scalar or a
+// matrixObject.
+type matrixValue struct {
+	key   string
+	value interface{}
+}
+
+// matrixObject is an object value of a matrix parameter. It keeps the order of the object keys.
+type matrixObject []matrixValue
+
+// matrixCombination is one job of a matrix build. It keeps the order of the matrix keys.
+type matrixCombination []matrixValue
+
+func (combination matrixCombination) get(key string) (interface{}, bool) {
+	for _, entry := range combination {
+		if entry.key == key {
+			return entry.value, true
+		}
+	}
+	return nil, false
+}
+
+// String describes the combination, e.g. "os=linux, go=1.19".
+func (combination matrixCombination) String() string {
+	parameters := make([]string, 0, len(combination))
+	for _, entry := range combination {
+		parameters = append(parameters, entry.key+"="+formatMatrixValue(entry.value))
+	}
+	return strings.Join(parameters, ", ")
+}
+
+// with returns a copy of the combination in which the value of the key is replaced or added.
+func (combination matrixCombination) with(key string, value interface{}) matrixCombination {
+	result := make(matrixCombination, 0, len(combination)+1)
+	replaced := false
+	for _, entry := range combination {
+		if entry.key == key {
+			result = append(result, matrixValue{key: key, value: value})
+			replaced = true
+		} else {
+			result = append(result, entry)
+		}
+	}
+	if !replaced {
+		result = append(result, matrixValue{key: key, value: value})
+	}
+	return result
+}
+
+// expandMatrix returns the combinations of a matrix following GitHub's rules: First the cartesian product of all
+// dimensions is built and the exclude entries are removed. Then each include entry is added to all original
+// combinations whose original values it does not overwrite. If it can't be added to any of them, it becomes a new
+// combination.
+func expandMatrix(matrix *yaml.Node) ([]matrixCombination, error) {
+	matrix = resolveAlias(matrix)
+	if matrix.Kind == yaml.ScalarNode {
+		return nil, unresolvableMatrixError{fmt.Sprintf("the matrix is defined by the expression '%v' that can only be evaluated at runtime", matrix.Value)}
+	} else if matrix.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the matrix in line %d is not an object", matrix.Line)
+	}
+	var dimensionKeys []string
+	combinations := []matrixCombination{{}}
+	var includes, excludes []matrixObject
+	for index := 0; index+1 < len(matrix.Content); index += 2 {
+		key := matrix.Content[index].Value
+		valueNode := matrix.Content[index+1]
+		var err error
+		switch key {
+		case "include":
+			includes, err = readMatrixEntryList(key, valueNode)
+		case "exclude":
+			excludes, err = readMatrixEntryList(key, valueNode)
+		default:
+			var values []interface{}
+			values, err = readMatrixDimension(key, valueNode)
+			dimensionKeys = append(dimensionKeys, key)
+			combinations = addDimensionToCombinations(combinations, key, values)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(dimensionKeys) == 0 {
+		combinations = nil
+	}
+	combinations = removeExcludedCombinations(combinations, excludes)
+	return addIncludedCombinations(combinations, dimensionKeys, includes), nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func readMatrixDimension(key string, node *yaml.Node) ([]interface{}, error) {
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.SequenceNode:
+		values := make([]interface{}, 0, len(node.Content))
+		for _, valueNode := range node.Content {
+			value, err := readMatrixValue(valueNode)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case yaml.ScalarNode:
+		return nil, unresolvableMatrixError{fmt.Sprintf("the matrix parameter '%v' is defined by the expression '%v' that can only be evaluated at runtime", key, node.Value)}
+	default:
+		return nil, fmt.Errorf("the matrix parameter '%v' is not a list", key)
+	}
+}
+
+func readMatrixEntryList(key string, node *yaml.Node) ([]matrixObject, error) {
+	node = resolveAlias(node)
+	if node.Kind == yaml.ScalarNode {
+		return nil, unresolvableMatrixError{fmt.Sprintf("the matrix %v is defined by the expression '%v' that can only be evaluated at runtime", key, node.Value)}
+	} else if node.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("the matrix %v is not a list", key)
+	}
+	var result []matrixObject
+	for _, entryNode := range node.Content {
+		entry, err := readMatrixValue(entryNode)
+		if err != nil {
+			return nil, err
+		}
+		object, isObject := entry.(matrixObject)
+		if !isObject {
+			return nil, fmt.Errorf("the matrix %v contains an entry that is not an object", key)
+		}
+		result = append(result, object)
+	}
+	return result, nil
+}
+
+// readMatrixValue reads a matrix value. Scalars keep their original text, so that `3.10` is not converted to `3.1`.
+func readMatrixValue(node *yaml.Node) (interface{}, error) {
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return "", nil
+		}
+		return node.Value, nil
+	case yaml.MappingNode:
+		object := matrixObject{}
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			value, err := readMatrixValue(node.Content[index+1])
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, matrixValue{key: node.Content[index].Value, value: value})
+		}
+		return object, nil
+	default:
+		return nil, fmt.Errorf("the matrix value in line %d is not supported. Only scalars and objects are supported", node.Line)
+	}
+}
+
+func addDimensionToCombinations(combinations []matrixCombination, key string, values []interface{}) []matrixCombination {
+	var result []matrixCombination
+	for _, combination := range combinations {
+		for _, value := range values {
+			result = append(result, combination.with(key, value))
+		}
+	}
+	return result
+}
+
+func removeExcludedCombinations(combinations []matrixCombination, excludes []matrixObject) []matrixCombination {
+	var result []matrixCombination
+	for _, combination := range combinations {
+		excluded := false
+		for _, exclude := range excludes {
+			if combinationMatches(combination, exclude) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			result = append(result, combination)
+		}
+	}
+	return result
+}
+
+// combinationMatches checks if the combination has all values of the exclude entry.
+func combinationMatches(combination matrixCombination, exclude matrixObject) bool {
+	for _, entry := range exclude {
+		combinationValue, found := combination.get(entry.key)
+		if !found || !matrixValuesEqual(combinationValue, entry.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func addIncludedCombinations(combinations []matrixCombination, dimensionKeys []string, includes []matrixObject) []matrixCombination {
+	originalCount := len(combinations)
+	for _, include := range includes {
+		added := false
+		for index := 0; index < originalCount; index++ {
+			if overwritesOriginalValue(combinations[index], dimensionKeys, include) {
+				continue
+			}
+			combinations[index] = addIncludeToCombination(combinations[index], include)
+			added = true
+		}
+		if !added {
+			combinations = append(combinations, addIncludeToCombination(matrixCombination{}, include))
+		}
+	}
+	return combinations
+}
+
+func overwritesOriginalValue(combination matrixCombination, dimensionKeys []string, include matrixObject) bool {
+	for _, entry := range include {
+		if !containsString(dimensionKeys, entry.key) {
+			continue
+		}
+		originalValue, _ := combination.get(entry.key)
+		if !matrixValuesEqual(originalValue, entry.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func addIncludeToCombination(combination matrixCombination, include matrixObject) matrixCombination {
+	for _, entry := range include {
+		combination = combination.with(entry.key, entry.value)
+	}
+	return combination
+}
+
+func matrixValuesEqual(left interface{}, right interface{}) bool {
+	leftObject, leftIsObject := left.(matrixObject)
+	rightObject, rightIsObject := right.(matrixObject)
+	if leftIsObject != rightIsObject {
+		return false
+	} else if !leftIsObject {
+		return left == right
+	}
+	if len(leftObject) != len(rightObject) {
+		return false
+	}
+	for _, entry := range leftObject {
+		if !combinationMatches(matrixCombination(rightObject), matrixObject{entry}) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TriggerDefinition describes the events that start a workflow.
+type TriggerDefinition struct {
+	TriggerOnPr              bool
+	TriggerOnPushToBranches  []string
+	TriggerOnPushToAnyBranch bool
+	// Events contains the filters of the events by event name, e.g. "pull_request".
+	Events map[string]*eventFilter
+}
+
+// eventFilter contains the filters of an event. Empty lists mean that the event is not filtered.
+type eventFilter struct {
+	Branches       []string
+	BranchesIgnore []string
+	Tags           []string
+	TagsIgnore     []string
+	Paths          []string
+	PathsIgnore    []string
+	Types          []string
+}
+
+const pullRequestEvent = "pull_request"
+const pullRequestTargetEvent = "pull_request_target"
+const pushEvent = "push"
+const mergeGroupEvent = "merge_group"
+const workflowCallEvent = "workflow_call"
+
+// defaultPullRequestTypes are the activity types that start pull request workflows if no types are specified.
+var defaultPullRequestTypes = []string{"opened", "synchronize", "reopened"}
+
+func getTriggersOfWorkflowDefinition(parsedYaml *workflowDefinitionInt) (*TriggerDefinition, error) {
+	if trigger, isString := parsedYaml.On.(string); isString {
+		return tryReadingTriggersFromArray([]interface{}{trigger}), nil
+	} else if triggersFromArray := tryReadingTriggersFromArray(parsedYaml.On); triggersFromArray != nil {
+		return triggersFromArray, nil
+	} else if triggersFromMap := tryReadingTriggersFromMap(parsedYaml.On); triggersFromMap != nil {
+		return triggersFromMap, nil
+	} else {
+		return nil, fmt.Errorf("the GitHub workflow '%v'uses an unsupported trigger definition style", parsedYaml.Name)
+	}
+}
+
+func tryReadingTriggersFromArray(parsedYaml interface{}) *TriggerDefinition {
+	triggersAsList, ok := parsedYaml.([]interface{})
+	if ok && triggersAsList != nil {
+		result := TriggerDefinition{Events: map[string]*eventFilter{}}
+		for _, trigger := range triggersAsList {
+			result.addEvent(strings.ToLower(trigger.(string)), &eventFilter{})
+		}
+		return &result
+	}
+	return nil
+}
+
+func tryReadingTriggersFromMap(parsedYaml interface{}) *TriggerDefinition {
+	triggersAsMap, ok := parsedYaml.(map[string]interface{})
+	if ok {
+		result := TriggerDefinition{Events: map[string]*eventFilter{}}
+		for trigger, triggerParams := range triggersAsMap {
+			result.addEvent(strings.ToLower(trigger), readEventFilter(triggerParams))
+		}
+		return &result
+	}
+	return nil
+}
+
+func (trigger *TriggerDefinition) addEvent(event string, filter *eventFilter) {
+	trigger.Events[event] = filter
+	if event == pullRequestEvent {
+		trigger.TriggerOnPr = true
+	} else if event == pushEvent {
+		if filter.Branches != nil {
+			trigger.TriggerOnPushToBranches = append(trigger.TriggerOnPushToBranches, filter.Branches...)
+		} else if !filter.onlyFiltersTags() {
+			trigger.TriggerOnPushToAnyBranch = true
+		}
+	}
+}
+
+// onlyFiltersTags checks if the filter only contains tag filters. GitHub then doesn't run the push trigger for branches.
+func (filter *eventFilter) onlyFiltersTags() bool {
+	return filter.Branches == nil && filter.BranchesIgnore == nil && (filter.Tags != nil || filter.TagsIgnore != nil)
+}
+
+func readEventFilter(triggerParams interface{}) *eventFilter {
+	triggerParamMap, ok := triggerParams.(map[string]interface{})
+	if !ok {
+		return &eventFilter{}
+	}
+	return &eventFilter{
+		Branches:       readStringList(triggerParamMap["branches"]),
+		BranchesIgnore: readStringList(triggerParamMap["branches-ignore"]),
+		Tags:           readStringList(triggerParamMap["tags"]),
+		TagsIgnore:     readStringList(triggerParamMap["tags-ignore"]),
+		Paths:          readStringList(triggerParamMap["paths"]),
+		PathsIgnore:    readStringList(triggerParamMap["paths-ignore"]),
+		Types:          readStringList(triggerParamMap["types"]),
+	}
+}
+
+func readStringList(value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, entry := range value {
+			result = append(result, convertValueToString(entry))
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// getReportingDecision decides if the checks of the workflow are reported for every pull request to the given branch.
+// If the checks are only reported for some pull requests, e.g. because of path filters, it returns false and the reason.
+// Such checks must not be required since they block the pull requests for which they are not reported forever.
+func (trigger *TriggerDefinition) getReportingDecision(branch string) (bool, string) {
+	var reasons []string
+	runsForPullRequests := false
+	for _, event := range []string{pullRequestEvent, pullRequestTargetEvent, pushEvent} {
+		filter, found := trigger.Events[event]
+		if !found {
+			continue
+		}
+		runsForPullRequests = true
+		reportsAlways, reason := filter.getReportingDecision(event, branch)
+		if reportsAlways {
+			return true, ""
+		} else if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	if _, found := trigger.Events[mergeGroupEvent]; found && !runsForPullRequests {
+		reasons = append(reasons, "the workflow only runs in the merge queue (merge_group trigger)")
+	}
+	return false, strings.Join(reasons, ", ")
+}
+
+// getReportingEvents returns the events whose runs report the checks of the workflow for every pull request to the
+// branch.
+func (trigger *TriggerDefinition) getReportingEvents(branch string) []string {
+	var result []string
+	for _, event := range []string{pullRequestEvent, pullRequestTargetEvent, pushEvent} {
+		if filter, found := trigger.Events[event]; found {
+			if reportsAlways, _ := filter.getReportingDecision(event, branch); reportsAlways {
+				result = append(result, event)
+			}
+		}
+	}
+	return result
+}
+
+func (filter *eventFilter) getReportingDecision(event string, branch string) (bool, string) {
+	if event == pushEvent {
+		// the push runs for the head branch of the pull request which we don't know in advance
+		if filter.Branches != nil || filter.onlyFiltersTags() {
+			return false, ""
+		}
+	} else {
+		if !filter.matchesBranch(branch) {
+			return false, ""
+		}
+		if !filter.includesType("synchronize") {
+			return false, fmt.Sprintf("the %v trigger does not run when new commits are pushed (types: %v)", event, strings.Join(filter.Types, ", "))
+		}
+	}
+	if filter.Paths != nil || filter.PathsIgnore != nil {
+		return false, fmt.Sprintf("the %v trigger only runs for changes of specific paths", event)
+	}
+	return true, ""
+}
+
+func (filter *eventFilter) matchesBranch(branch string) bool {
+	if filter.Branches != nil {
+		return matchesFilterPatterns(filter.Branches, branch)
+	}
+	return !matchesAnyPattern(filter.BranchesIgnore, branch)
+}
+
+func (filter *eventFilter) includesType(activityType string) bool {
+	types := filter.Types
+	if types == nil {
+		types = defaultPullRequestTypes
+	}
+	return containsString(types, activityType)
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WorkflowTriggersSuite struct {
+	suite.Suite
+}
+
+func TestWorkflowTriggersSuite(t *testing.T) {
+	suite.Run(t, new(WorkflowTriggersSuite))
+}
+
+func (suite *WorkflowTriggersSuite) parseTriggers(content string) *TriggerDefinition {
+	definition, err := WorkflowDefinitionParser{}.ParseWorkflowDefinition(content)
+	suite.NoError(err)
+	return definition.Trigger
+}
+
+func (suite *WorkflowTriggersSuite) TestPullRequestReportsChecks() {
+	trigger := suite.parseTriggers(`
+on: pull_request
+`)
+	reportsChecks, reason := trigger.getReportingDecision("main")
+	suite.True(reportsChecks)
+	suite.Empty(reason)
+}
+
+func (suite *WorkflowTriggersSuite) TestPullRequestTargetReportsChecks() {
+	trigger := suite.parseTriggers(`
+on:
+  pull_request_target:
+    branches: [main]
+`)
+	reportsChecks, _ := trigger.getReportingDecision("main")
+	suite.True(reportsChecks)
+	suite.False(trigger.TriggerOnPr)
+}
+
+func (suite *WorkflowTriggersSuite) TestPullRequestForOtherBranch() {
+	trigger := suite.parseTriggers(`
+on:
+  pull_request:
+    branches: ["release/*"]
+`)
+	reportsChecks, reason := trigger.getReportingDecision("main")
+	suite.False(reportsChecks)
+	suite.Empty(reason)
+	reportsChecks, _ = trigger.getReportingDecision("release/1.0")
+	suite.True(reportsChecks)
+}
+
+func (suite *WorkflowTriggersSuite) TestPullRequestWithIgnoredBranch() {
+	trigger := suite.parseTriggers(`
+on:
+  pull_request:
+    branches-ignore: [main]
+`)
+	reportsChecks, _ := trigger.getReportingDecision("main")
+	suite.False(reportsChecks)
+	reportsChecks, _ = trigger.getReportingDecision("develop")
+	suite.True(reportsChecks)
+}
+
+func (suite *WorkflowTriggersSuite) TestPullRequestWithPathFilter() {
+	trigger := suite.parseTriggers(`
+on:
+  pull_request:
+    paths: ["doc/**"]
+`)
+	reportsChecks, reason := trigger.getReportingDecision("main")
+	suite.False(reportsChecks)
+	suite.Equal("the pull_request trigger only runs for changes of specific paths", reason)
+}
+
+func (suite *WorkflowTriggersSuite) TestPullRequestWithPathsIgnoreAndUnfilteredPush() {
+	trigger := suite.parseTriggers(`
+on:
+  pull_request:
+    paths-ignore: ["doc/**"]
+  push:
+`)
+	reportsChecks, reason := trigger.getReportingDecision("main")
+	suite.True(reportsChecks)
+	suite.Empty(reason)
+}
+
+func (suite *WorkflowTriggersSuite) TestPullRequestWithoutSynchronizeType() {
+	trigger := suite.parseTriggers(`
+on:
+  pull_request:
+    types: [opened, labeled]
+`)
+	reportsChecks, reason := trigger.getReportingDecision("main")
+	suite.False(reportsChecks)
+	suite.Equal("the pull_request trigger does not run when new commits are pushed (types: opened, labeled)", reason)
+}
+
+func (suite *WorkflowTriggersSuite) TestMergeGroupOnlyDoesNotReportChecksForPullRequests() {
+	trigger := suite.parseTriggers(`
+on:
+  merge_group:
+`)
+	reportsChecks, reason := trigger.getReportingDecision("main")
+	suite.False(reportsChecks)
+	suite.Equal("the workflow only runs in the merge queue (merge_group trigger)", reason)
+}
+
+func (suite *WorkflowTriggersSuite) TestMergeGroupWithPullRequestReportsChecks() {
+	trigger := suite.parseTriggers(`
+on:
+  pull_request:
+  merge_group:
+`)
+	reportsChecks, reason := trigger.getReportingDecision("main")
+	suite.True(reportsChecks)
+	suite.Empty(reason)
+}
+
+func (suite *WorkflowTriggersSuite) TestPushOfTagsDoesNotReportChecks() {
+	trigger := suite.parseTriggers(`
+on:
+  push:
+    tags: ["v*"]
+`)
+	reportsChecks, _ := trigger.getReportingDecision("main")
+	suite.False(reportsChecks)
+	suite.False(trigger.TriggerOnPushToAnyBranch)
+}
+
+func (suite *WorkflowTriggersSuite) TestPushWithTagsIgnoreDoesNotReportChecks() {
+	trigger := suite.parseTriggers(`
+on:
+  push:
+    tags-ignore: ["v*"]
+`)
+	reportsChecks, _ := trigger.getReportingDecision("main")
+	suite.False(reportsChecks)
+}
+
+func (suite *WorkflowTriggersSuite) TestPushOfBranchesAndTagsReportsChecks() {
+	trigger := suite.parseTriggers(`
+on:
+  push:
+    branches-ignore: ["dependabot/**"]
+    tags: ["v*"]
+`)
+	reportsChecks, _ := trigger.getReportingDecision("main")
+	suite.True(reportsChecks)
+	suite.True(trigger.TriggerOnPushToAnyBranch)
+}
+
+func (suite *WorkflowTriggersSuite) TestPushToSpecificBranchDoesNotReportChecks() {
+	trigger := suite.parseTriggers(`
+on:
+  push:
+    branches: [main]
+`)
+	reportsChecks, _ := trigger.getReportingDecision("main")
+	suite.False(reportsChecks)
+}